@@ -0,0 +1,69 @@
+// Package admin provides HTTP handlers exposing application internals for operators.
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/platforma-dev/platforma/application"
+	"github.com/platforma-dev/platforma/database"
+	"github.com/platforma-dev/platforma/httpserver"
+	"github.com/platforma-dev/platforma/log"
+)
+
+type healther interface {
+	Health(context.Context) *application.ApplicationHealth
+}
+
+type serviceLister interface {
+	Services() []application.ServiceInfo
+}
+
+type serviceRestarter interface {
+	RestartService(serviceName string) error
+}
+
+type databaseLister interface {
+	Databases() map[string]*database.Database
+}
+
+type app interface {
+	healther
+	serviceLister
+	serviceRestarter
+	databaseLister
+}
+
+// New creates a handler group with admin endpoints for the given application:
+//
+//	GET  /services                  registered services with their state
+//	POST /services/{name}/restart   restarts the running service
+//	GET  /startup-tasks             startup tasks with their outcomes
+//	GET  /databases                 databases, repositories and migrations
+//	GET  /build                     build info of the binary
+//	POST /jobs/{name}/run           triggers an immediate run of a scheduler service
+//
+// The endpoints are not protected, mount the group behind an authentication middleware
+// or on a server that is not reachable publicly.
+func New(a app) *httpserver.HandlerGroup {
+	group := httpserver.NewHandlerGroup()
+	group.Handle("GET /services", NewServicesHandler(a, a))
+	group.Handle("POST /services/{name}/restart", NewRestartServiceHandler(a))
+	group.Handle("GET /startup-tasks", NewStartupTasksHandler(a))
+	group.Handle("GET /databases", NewDatabasesHandler(a))
+	group.Handle("GET /build", NewBuildHandler())
+	group.Handle("POST /jobs/{name}/run", NewRunJobHandler(a))
+
+	return group
+}
+
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	err := json.NewEncoder(w).Encode(data)
+	if err != nil {
+		log.ErrorContext(r.Context(), "failed to decode response to json", "error", err)
+	}
+}
@@ -0,0 +1,186 @@
+package admin_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/platforma-dev/platforma/admin"
+	"github.com/platforma-dev/platforma/application"
+	"github.com/platforma-dev/platforma/scheduler"
+)
+
+func serve(t *testing.T, handler http.Handler, method, path string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+
+	return w
+}
+
+func decode[T any](t *testing.T, w *httptest.ResponseRecorder) T {
+	t.Helper()
+
+	var data T
+	err := json.NewDecoder(w.Body).Decode(&data)
+	if err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	return data
+}
+
+func newRunningApplication(t *testing.T) *application.Application {
+	t.Helper()
+
+	app := application.New()
+	app.OnStartFunc(func(_ context.Context) error {
+		return nil
+	}, application.StartupTaskConfig{Name: "seed"})
+	app.OnStartFunc(func(_ context.Context) error {
+		return errors.New("boom")
+	}, application.StartupTaskConfig{Name: "warmup"})
+	app.RegisterService("api", application.RunnerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}), application.Critical())
+	app.RegisterService("job", scheduler.New(time.Hour, application.RunnerFunc(func(_ context.Context) error {
+		return nil
+	})), application.DependsOn("api"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go app.Run(ctx)
+
+	time.Sleep(50 * time.Millisecond)
+
+	return app
+}
+
+func TestAdmin(t *testing.T) {
+	t.Parallel()
+
+	t.Run("services", func(t *testing.T) {
+		t.Parallel()
+
+		handler := admin.New(newRunningApplication(t))
+
+		w := serve(t, handler, http.MethodGet, "/services")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", w.Code)
+		}
+
+		services := decode[[]struct {
+			Name      string   `json:"name"`
+			DependsOn []string `json:"dependsOn"`
+			Critical  bool     `json:"critical"`
+			Health    struct {
+				Status application.ServiceStatus `json:"status"`
+			} `json:"health"`
+		}](t, w)
+
+		if len(services) != 2 || services[0].Name != "api" || services[1].Name != "job" {
+			t.Fatalf("unexpected services: %+v", services)
+		}
+
+		if !services[0].Critical || services[0].Health.Status != application.ServiceStatusRunning {
+			t.Fatalf("unexpected api service: %+v", services[0])
+		}
+
+		if len(services[1].DependsOn) != 1 || services[1].DependsOn[0] != "api" {
+			t.Fatalf("unexpected job dependencies: %v", services[1].DependsOn)
+		}
+	})
+
+	t.Run("restart service", func(t *testing.T) {
+		t.Parallel()
+
+		app := newRunningApplication(t)
+		handler := admin.New(app)
+
+		w := serve(t, handler, http.MethodPost, "/services/api/restart")
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("expected status 202, got %d", w.Code)
+		}
+
+		time.Sleep(20 * time.Millisecond)
+
+		if restarts := app.Health(context.Background()).Services["api"].Restarts; restarts != 1 {
+			t.Fatalf("expected 1 restart, got %d", restarts)
+		}
+
+		w = serve(t, handler, http.MethodPost, "/services/unknown/restart")
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("expected status 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("startup tasks", func(t *testing.T) {
+		t.Parallel()
+
+		w := serve(t, admin.New(newRunningApplication(t)), http.MethodGet, "/startup-tasks")
+
+		tasks := decode[[]application.StartupTaskHealth](t, w)
+		if len(tasks) != 2 {
+			t.Fatalf("expected 2 startup tasks, got %d", len(tasks))
+		}
+
+		if tasks[0].Name != "seed" || tasks[0].Status != application.StartupTaskStatusSucceeded {
+			t.Fatalf("unexpected first task: %+v", tasks[0])
+		}
+
+		if tasks[1].Status != application.StartupTaskStatusFailed || tasks[1].Error != "boom" {
+			t.Fatalf("unexpected second task: %+v", tasks[1])
+		}
+	})
+
+	t.Run("databases", func(t *testing.T) {
+		t.Parallel()
+
+		w := serve(t, admin.New(newRunningApplication(t)), http.MethodGet, "/databases")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", w.Code)
+		}
+
+		if databases := decode[[]any](t, w); len(databases) != 0 {
+			t.Fatalf("expected no databases, got %v", databases)
+		}
+	})
+
+	t.Run("build", func(t *testing.T) {
+		t.Parallel()
+
+		w := serve(t, admin.New(application.New()), http.MethodGet, "/build")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", w.Code)
+		}
+
+		info := decode[struct {
+			GoVersion string `json:"goVersion"`
+		}](t, w)
+		if info.GoVersion == "" {
+			t.Fatal("expected go version in build info")
+		}
+	})
+
+	t.Run("run job", func(t *testing.T) {
+		t.Parallel()
+
+		handler := admin.New(newRunningApplication(t))
+
+		w := serve(t, handler, http.MethodPost, "/jobs/job/run")
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("expected status 202, got %d", w.Code)
+		}
+
+		w = serve(t, handler, http.MethodPost, "/jobs/api/run")
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("expected status 404 for non-job service, got %d", w.Code)
+		}
+	})
+}
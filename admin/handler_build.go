@@ -0,0 +1,54 @@
+package admin
+
+import (
+	"net/http"
+	"runtime/debug"
+)
+
+type module struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	Sum     string `json:"sum,omitempty"`
+}
+
+type buildInfo struct {
+	GoVersion string            `json:"goVersion"`
+	Path      string            `json:"path"`
+	Main      module            `json:"main"`
+	Settings  map[string]string `json:"settings"` // Build settings, e.g. vcs.revision
+	Deps      []module          `json:"deps"`
+}
+
+// BuildHandler responds with build info of the running binary.
+type BuildHandler struct{}
+
+// NewBuildHandler creates a new BuildHandler.
+func NewBuildHandler() *BuildHandler {
+	return &BuildHandler{}
+}
+
+func (h *BuildHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		http.Error(w, "build info is not available", http.StatusNotFound)
+		return
+	}
+
+	response := buildInfo{
+		GoVersion: info.GoVersion,
+		Path:      info.Path,
+		Main:      module{Path: info.Main.Path, Version: info.Main.Version, Sum: info.Main.Sum},
+		Settings:  make(map[string]string, len(info.Settings)),
+		Deps:      make([]module, 0, len(info.Deps)),
+	}
+
+	for _, setting := range info.Settings {
+		response.Settings[setting.Key] = setting.Value
+	}
+
+	for _, dep := range info.Deps {
+		response.Deps = append(response.Deps, module{Path: dep.Path, Version: dep.Version, Sum: dep.Sum})
+	}
+
+	writeJSON(w, r, http.StatusOK, response)
+}
@@ -0,0 +1,47 @@
+package admin
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+
+	"github.com/platforma-dev/platforma/database"
+)
+
+type databaseResponse struct {
+	Name         string                     `json:"name"`
+	Repositories []string                   `json:"repositories"`
+	Migrations   []database.MigrationStatus `json:"migrations"`
+	Error        string                     `json:"error,omitempty"` // Error of reading migrations state
+}
+
+// DatabasesHandler lists registered databases with their repositories and migrations.
+type DatabasesHandler struct {
+	databases databaseLister
+}
+
+// NewDatabasesHandler creates a new DatabasesHandler.
+func NewDatabasesHandler(databases databaseLister) *DatabasesHandler {
+	return &DatabasesHandler{databases: databases}
+}
+
+func (h *DatabasesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	databases := h.databases.Databases()
+
+	response := []databaseResponse{}
+	for _, dbName := range slices.Sorted(maps.Keys(databases)) {
+		db := databases[dbName]
+		dbResponse := databaseResponse{Name: dbName, Repositories: db.Repositories(), Migrations: []database.MigrationStatus{}}
+
+		migrations, err := db.MigrationStatus(r.Context())
+		if err != nil {
+			dbResponse.Error = err.Error()
+		} else {
+			dbResponse.Migrations = migrations
+		}
+
+		response = append(response, dbResponse)
+	}
+
+	writeJSON(w, r, http.StatusOK, response)
+}
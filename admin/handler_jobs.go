@@ -0,0 +1,52 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/platforma-dev/platforma/scheduler"
+)
+
+// jobTrigger is implemented by services that can be run on demand, e.g. scheduler.Scheduler.
+type jobTrigger interface {
+	Trigger() error
+}
+
+// RunJobHandler triggers an immediate run of the job service named in the path.
+type RunJobHandler struct {
+	services serviceLister
+}
+
+// NewRunJobHandler creates a new RunJobHandler.
+func NewRunJobHandler(services serviceLister) *RunJobHandler {
+	return &RunJobHandler{services: services}
+}
+
+func (h *RunJobHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	var job jobTrigger
+	for _, service := range h.services.Services() {
+		if trigger, ok := service.Runner.(jobTrigger); ok && service.Name == name {
+			job = trigger
+		}
+	}
+
+	if job == nil {
+		http.Error(w, "job not found", http.StatusNotFound)
+		return
+	}
+
+	err := job.Trigger()
+	if err != nil {
+		if errors.Is(err, scheduler.ErrNotRunning) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
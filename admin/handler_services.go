@@ -0,0 +1,67 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/platforma-dev/platforma/application"
+)
+
+type serviceResponse struct {
+	application.ServiceInfo
+
+	Health application.ServiceHealth `json:"health"`
+}
+
+// ServicesHandler lists registered services with their configuration and state.
+type ServicesHandler struct {
+	services serviceLister
+	health   healther
+}
+
+// NewServicesHandler creates a new ServicesHandler.
+func NewServicesHandler(services serviceLister, health healther) *ServicesHandler {
+	return &ServicesHandler{services: services, health: health}
+}
+
+func (h *ServicesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	health := h.health.Health(r.Context())
+
+	services := []serviceResponse{}
+	for _, service := range h.services.Services() {
+		response := serviceResponse{ServiceInfo: service}
+		if serviceHealth, ok := health.Services[service.Name]; ok {
+			response.Health = *serviceHealth
+		}
+		services = append(services, response)
+	}
+
+	writeJSON(w, r, http.StatusOK, services)
+}
+
+// RestartServiceHandler restarts the service named in the path.
+type RestartServiceHandler struct {
+	restarter serviceRestarter
+}
+
+// NewRestartServiceHandler creates a new RestartServiceHandler.
+func NewRestartServiceHandler(restarter serviceRestarter) *RestartServiceHandler {
+	return &RestartServiceHandler{restarter: restarter}
+}
+
+func (h *RestartServiceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	err := h.restarter.RestartService(r.PathValue("name"))
+	if err != nil {
+		switch {
+		case errors.Is(err, application.ErrUnknownService):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, application.ErrServiceNotRunning):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,26 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/platforma-dev/platforma/application"
+)
+
+// StartupTasksHandler lists startup tasks with their outcomes in registration order.
+type StartupTasksHandler struct {
+	health healther
+}
+
+// NewStartupTasksHandler creates a new StartupTasksHandler.
+func NewStartupTasksHandler(health healther) *StartupTasksHandler {
+	return &StartupTasksHandler{health: health}
+}
+
+func (h *StartupTasksHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	tasks := h.health.Health(r.Context()).StartupTasks
+	if tasks == nil {
+		tasks = []*application.StartupTaskHealth{}
+	}
+
+	writeJSON(w, r, http.StatusOK, tasks)
+}
@@ -2,9 +2,15 @@ package application
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"os/signal"
+	"runtime/debug"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -12,6 +18,9 @@ import (
 	"github.com/platforma-dev/platforma/log"
 )
 
+// ErrUnknownDatabase is returned when an operation refers to a database that is not registered.
+var ErrUnknownDatabase = errors.New("unknown database")
+
 // ErrDatabaseMigrationFailed is an error type that represents a failed database migration.
 type ErrDatabaseMigrationFailed struct {
 	err error
@@ -27,68 +36,241 @@ func (e *ErrDatabaseMigrationFailed) Unwrap() error {
 	return e.err
 }
 
+// ErrConfigLoadFailed is an error type that represents a failed configuration loading.
+type ErrConfigLoadFailed struct {
+	err error
+}
+
+// Error returns the formatted error message for ErrConfigLoadFailed.
+func (e *ErrConfigLoadFailed) Error() string {
+	return fmt.Sprintf("failed to load config: %v", e.err)
+}
+
+// Unwrap returns the underlying error for ErrConfigLoadFailed.
+func (e *ErrConfigLoadFailed) Unwrap() error {
+	return e.err
+}
+
+type configLoader interface {
+	Load(context.Context) error
+}
+
 // Application manages startup tasks and services for the application lifecycle.
 type Application struct {
-	startupTasks   []startupTask
-	services       map[string]Runner
-	healthcheckers map[string]Healthchecker
-	databases      map[string]*database.Database
-	health         *ApplicationHealth
+	startupTasks        []startupTask
+	shutdownTasks       []shutdownTask
+	shutdownTimeout     time.Duration
+	services            map[string]*service
+	healthchecks        map[string]*healthCheck
+	healthCheckInterval time.Duration
+	healthCheckTimeout  time.Duration
+	readiness           map[string]ReadinessChecker
+	databases           map[string]*database.Database
+	migrateOptions      map[string][]database.MigrateOption // Options of registered databases used for every migration
+	configs             map[string]configLoader
+	health              *ApplicationHealth
+	observers           []Observer
+	commands            map[string]*command
+	container           *Container
+	domainProviders     []domainProvider
+	output              io.Writer // Destination of command output
+	flags               []string  // Application flags of the executed command, see Execute
+
+	statesMu sync.Mutex
+	states   map[string]*serviceState // States of services in the current run
 }
 
-// New creates and returns a new Application instance.
-func New() *Application {
-	return &Application{services: make(map[string]Runner), healthcheckers: make(map[string]Healthchecker), databases: make(map[string]*database.Database), health: NewApplicationHealth()}
+// Option configures an Application.
+type Option func(*Application)
+
+// WithShutdownTimeout limits the time the application has to stop all services and run shutdown tasks.
+// If the timeout expires, Run returns ErrShutdownTimeout. By default there is no limit.
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(a *Application) {
+		a.shutdownTimeout = timeout
+	}
+}
+
+// WithHealthCheckInterval sets the default time between two consecutive runs of every health check.
+// By default health checks run every 30 seconds.
+func WithHealthCheckInterval(interval time.Duration) Option {
+	return func(a *Application) {
+		a.healthCheckInterval = interval
+	}
+}
+
+// WithHealthCheckTimeout sets the default maximum duration of a single health check.
+// By default health checks time out after 5 seconds.
+func WithHealthCheckTimeout(timeout time.Duration) Option {
+	return func(a *Application) {
+		a.healthCheckTimeout = timeout
+	}
 }
 
-// Health returns the current health status of the application.
-func (a *Application) Health(ctx context.Context) *ApplicationHealth {
-	for hcName, hc := range a.healthcheckers {
-		a.health.SetServiceData(hcName, hc.Healthcheck(ctx))
+// WithOutput sets the writer that receives output of commands run by Execute, such as
+// the list of commands, usage messages and migration status. By default it is os.Stdout.
+func WithOutput(w io.Writer) Option {
+	return func(a *Application) {
+		a.output = w
 	}
-	return a.health
+}
+
+// New creates and returns a new Application instance.
+func New(opts ...Option) *Application {
+	app := &Application{services: make(map[string]*service), healthchecks: make(map[string]*healthCheck), readiness: make(map[string]ReadinessChecker), databases: make(map[string]*database.Database), migrateOptions: make(map[string][]database.MigrateOption), configs: make(map[string]configLoader), commands: make(map[string]*command), container: NewContainer(), output: os.Stdout, health: NewApplicationHealth()}
+
+	for _, opt := range opts {
+		opt(app)
+	}
+
+	return app
+}
+
+// Health returns a snapshot of the current health status of the application.
+// Health checks are not run by this method, their latest cached results are returned instead.
+func (a *Application) Health(_ context.Context) *ApplicationHealth {
+	health := a.health.Snapshot()
+	health.Status = a.healthStatus(health)
+	return health
+}
+
+// SubscribeHealth registers a function that is called every time a service changes its status.
+// The function is called synchronously and must not block. Call the returned function to unsubscribe.
+func (a *Application) SubscribeHealth(fn func(ServiceStatusChange)) func() {
+	return a.health.Subscribe(fn)
 }
 
 // OnStart registers a new startup task with the given runner and configuration.
 func (a *Application) OnStart(task Runner, config StartupTaskConfig) {
 	a.startupTasks = append(a.startupTasks, startupTask{task, config})
+	a.health.AddStartupTask(config.Name)
 }
 
 func (a *Application) OnStartFunc(task RunnerFunc, config StartupTaskConfig) {
 	a.startupTasks = append(a.startupTasks, startupTask{task, config})
+	a.health.AddStartupTask(config.Name)
+}
+
+// OnStop registers a new shutdown task with the given runner and configuration.
+// Shutdown tasks run after all services are stopped, in reverse registration order.
+// They also run if the application fails to start or services don't stop within the shutdown timeout.
+func (a *Application) OnStop(task Runner, config ShutdownTaskConfig) {
+	a.shutdownTasks = append(a.shutdownTasks, shutdownTask{task, config})
+}
+
+// OnStopFunc registers a new shutdown task with the given function and configuration.
+func (a *Application) OnStopFunc(task RunnerFunc, config ShutdownTaskConfig) {
+	a.shutdownTasks = append(a.shutdownTasks, shutdownTask{task, config})
+}
+
+// Services returns all registered services ordered by name.
+func (a *Application) Services() []ServiceInfo {
+	services := make([]ServiceInfo, 0, len(a.services))
+	for serviceName, service := range a.services {
+		services = append(services, ServiceInfo{
+			Name:      serviceName,
+			DependsOn: slices.Clone(service.config.dependsOn),
+			Optional:  service.config.optional,
+			Critical:  service.config.critical,
+			Runner:    service.runner,
+		})
+	}
+
+	slices.SortFunc(services, func(a, b ServiceInfo) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return services
+}
+
+// Container returns the dependency container of the application.
+func (a *Application) Container() *Container {
+	return a.container
+}
+
+// Databases returns all registered databases by their names.
+func (a *Application) Databases() map[string]*database.Database {
+	return maps.Clone(a.databases)
+}
+
+// RestartService stops the running service and starts it again.
+// The restart does not count towards the attempts of the service restart policy.
+func (a *Application) RestartService(serviceName string) error {
+	if _, ok := a.services[serviceName]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownService, serviceName)
+	}
+
+	a.statesMu.Lock()
+	state, ok := a.states[serviceName]
+	a.statesMu.Unlock()
+
+	if !ok || !state.requestRestart(func() { a.health.StopService(serviceName) }) {
+		return fmt.Errorf("%w: %s", ErrServiceNotRunning, serviceName)
+	}
+
+	return nil
+}
+
+// RegisterConfig adds a configuration to the application.
+// All registered configurations are loaded and validated before databases are migrated and startup tasks run.
+func (a *Application) RegisterConfig(configName string, config configLoader) {
+	a.configs[configName] = config
+}
+
+// RegisterHealthCheck adds a named health check to the application.
+// Health checks run in background while the application is running and their results are cached.
+// Registering a check with the name of an existing one replaces it.
+func (a *Application) RegisterHealthCheck(checkName string, checker Healthchecker, config HealthCheckConfig) {
+	a.healthchecks[checkName] = &healthCheck{checker: checker, config: config}
 }
 
 // RegisterDatabase adds a database to the application.
-func (a *Application) RegisterDatabase(dbName string, db *database.Database) {
+// Database health is checked in background under the "database:<dbName>" name.
+// The database is provided in the application container under dbName.
+// Migrate options, e.g. database.LockTimeout or database.AllowDrift, are used whenever the application
+// migrates the database, on startup and by the migrate command.
+func (a *Application) RegisterDatabase(dbName string, db *database.Database, opts ...database.MigrateOption) {
 	a.databases[dbName] = db
+	a.migrateOptions[dbName] = opts
+	ProvideNamed(a.container, dbName, func(*Container) (*database.Database, error) {
+		return db, nil
+	})
+	a.RegisterHealthCheck("database:"+dbName, &databaseHealthchecker{db: db}, HealthCheckConfig{})
 }
 
 // RegisterRepository adds a repository to the application.
-func (a *Application) RegisterRepository(dbName string, repoName string, repository any) {
-	a.databases[dbName].RegisterRepository(repoName, repository)
+// Options can be used to declare repositories that have to be migrated first.
+func (a *Application) RegisterRepository(dbName string, repoName string, repository any, opts ...database.RepositoryOption) {
+	a.databases[dbName].RegisterRepository(repoName, repository, opts...)
 }
 
 // RegisterService adds a named service to the application.
-func (a *Application) RegisterService(serviceName string, service Runner) {
+// Options can be used to declare dependencies on other services.
+func (a *Application) RegisterService(serviceName string, runner Runner, opts ...ServiceOption) {
+	config := serviceConfig{}
+	for _, opt := range opts {
+		opt(&config)
+	}
 
-	a.services[serviceName] = service
-	a.health.Services[serviceName] = &ServiceHealth{Status: ServiceStatusNotStarted}
+	a.services[serviceName] = &service{runner: runner, config: config}
+	a.health.AddService(serviceName)
 
-	healthcheckerService, ok := service.(Healthchecker)
+	healthcheckerService, ok := runner.(Healthchecker)
 	if ok {
-		a.healthcheckers[serviceName] = healthcheckerService
+		a.RegisterHealthCheck(serviceName, healthcheckerService, HealthCheckConfig{})
 	}
-}
 
-func (a *Application) RegisterDomain(name, dbName string, domain Domain) {
-	if dbName != "" {
-		repository := domain.GetRepository()
-		a.RegisterRepository(dbName, name+"_repository", repository)
+	readinessService, ok := runner.(ReadinessChecker)
+	if ok {
+		a.readiness[serviceName] = readinessService
 	}
 }
 
 // Run executes all startup tasks and services in the application.
-// It returns an error if any startup task configured to abort on error fails.
+// Services are started in dependency order and stopped in reverse order,
+// after that shutdown tasks are executed.
+// It returns an error if service dependencies are invalid, if any startup task
+// configured to abort on error fails or if a critical service fails.
 func (a *Application) Run(ctx context.Context) error {
 	if ctx == nil {
 		ctx = context.Background()
@@ -97,61 +279,340 @@ func (a *Application) Run(ctx context.Context) error {
 	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt, os.Kill)
 	defer cancel()
 
+	// Critical services stop the whole application through failApplication
+	ctx, stopApplication := context.WithCancel(ctx)
+	defer stopApplication()
+
+	failure := &applicationFailure{}
+	failApplication := func(err error) {
+		failure.set(err)
+		stopApplication()
+	}
+
 	log.InfoContext(ctx, "starting application", "startupTasks", len(a.startupTasks))
 
-	for dbName, db := range a.databases {
+	// Shutdown tasks run even if the application fails to start, e.g. to flush logs or release resources
+	err := a.loadConfigs(ctx)
+	if err != nil {
+		return a.shutdown(ctx, err)
+	}
+
+	err = a.resolveDomains(ctx)
+	if err != nil {
+		return a.shutdown(ctx, err)
+	}
+
+	// Domains may contribute services, so the order is known only after they are resolved
+	serviceOrder, err := sortServices(a.services)
+	if err != nil {
+		return a.shutdown(ctx, err)
+	}
+
+	err = a.migrateDatabases(ctx, slices.Sorted(maps.Keys(a.databases)))
+	if err != nil {
+		return a.shutdown(ctx, err)
+	}
+
+	err = a.runStartupTasks(ctx)
+	if err != nil {
+		return a.shutdown(ctx, err)
+	}
+
+	// Services get contexts detached from ctx cancellation so that they
+	// can be stopped one by one in reverse dependency order.
+	servicesCtx := context.WithoutCancel(ctx)
+	states := make(map[string]*serviceState, len(serviceOrder))
+	defer func() {
+		for _, state := range states {
+			state.cancel()
+		}
+	}()
+
+	serviceContexts := make(map[string]context.Context, len(serviceOrder))
+	for _, serviceName := range serviceOrder {
+		serviceCtx, cancelService := context.WithCancel(context.WithValue(servicesCtx, log.ServiceNameKey, serviceName))
+		serviceContexts[serviceName] = serviceCtx
+		states[serviceName] = newServiceState(cancelService)
+	}
+
+	a.statesMu.Lock()
+	a.states = states
+	a.statesMu.Unlock()
+
+	for _, serviceName := range serviceOrder {
+		go a.startService(serviceContexts[serviceName], serviceName, states, failApplication)
+	}
+
+	a.health.StartApplication()
+
+	healthCtx, stopHealthChecks := context.WithCancel(ctx)
+	defer stopHealthChecks()
+	healthChecksDone := a.startHealthChecks(healthCtx)
+
+	allDone := make(chan struct{})
+	go func() {
+		for _, state := range states {
+			<-state.done
+		}
+		close(allDone)
+	}()
+
+	select {
+	case <-ctx.Done():
+	case <-allDone:
+	}
+
+	log.InfoContext(ctx, "shutting down application")
+	a.emit(ctx, ShutdownInitiated{EventTime: newEventTime(), Err: failure.get()})
+
+	stopHealthChecks()
+	<-healthChecksDone
+
+	shutdownCtx, cancelShutdown := a.shutdownContext(ctx)
+	defer cancelShutdown()
+
+	// Shutdown tasks run even if services didn't stop in time, within the same deadline
+	err = a.stopServices(shutdownCtx, serviceOrder, states)
+	err = errors.Join(err, a.runShutdownTasks(shutdownCtx))
+
+	if err != nil {
+		log.ErrorContext(ctx, "error in application shutdown", "error", err)
+		return errors.Join(failure.get(), err)
+	}
+
+	return failure.get()
+}
+
+// loadConfigs loads and validates all registered configurations.
+func (a *Application) loadConfigs(ctx context.Context) error {
+	for configName, config := range a.configs {
+		log.InfoContext(ctx, "loading config", "config", configName)
+		err := config.Load(ctx)
+		if err != nil {
+			log.ErrorContext(ctx, "error in config loading", "error", err, "config", configName)
+			return &ErrConfigLoadFailed{err: err}
+		}
+
+		if stringer, ok := config.(fmt.Stringer); ok {
+			log.DebugContext(ctx, "config loaded", "config", configName, "values", stringer.String())
+		}
+	}
+
+	return nil
+}
+
+// migrateDatabases migrates registered databases with the given names one by one.
+func (a *Application) migrateDatabases(ctx context.Context, dbNames []string) error {
+	for _, dbName := range dbNames {
+		db, ok := a.databases[dbName]
+		if !ok {
+			return &ErrDatabaseMigrationFailed{err: fmt.Errorf("%w: %s", ErrUnknownDatabase, dbName)}
+		}
+
 		log.InfoContext(ctx, "migrating database", "database", dbName)
-		err := db.Migrate(ctx)
+		a.emit(ctx, MigrationStarted{EventTime: newEventTime(), Database: dbName})
+
+		startedAt := time.Now()
+		err := db.Migrate(ctx, a.migrateOptions[dbName]...)
+		a.emit(ctx, MigrationFinished{EventTime: newEventTime(), Database: dbName, Duration: time.Since(startedAt), Err: err})
 		if err != nil {
 			log.ErrorContext(ctx, "error in database migration", "error", err, "database", dbName)
 			return &ErrDatabaseMigrationFailed{err: err}
 		}
 	}
 
-	for i, task := range a.startupTasks {
-		log.InfoContext(ctx, "running task", "task", task.config.Name, "index", i)
+	return nil
+}
 
-		taskCtx := context.WithValue(ctx, log.StartupTaskKey, task.config.Name)
+// shutdownContext returns a context for the application shutdown.
+// It is not cancelled together with ctx and expires after the configured shutdown timeout.
+func (a *Application) shutdownContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	shutdownCtx := context.WithoutCancel(ctx)
 
-		err := task.runner.Run(taskCtx)
-		if err != nil {
-			log.ErrorContext(ctx, "error in startup task", "error", err, "task", task.config.Name)
+	if a.shutdownTimeout > 0 {
+		return context.WithTimeout(shutdownCtx, a.shutdownTimeout)
+	}
 
-			if task.config.AbortOnError {
-				return &ErrStartupTaskFailed{err: err}
-			}
+	return context.WithCancel(shutdownCtx)
+}
+
+// shutdown runs shutdown tasks within the shutdown deadline after the application or a command stopped with err.
+// It returns err joined with the shutdown error.
+func (a *Application) shutdown(ctx context.Context, err error) error {
+	shutdownCtx, cancelShutdown := a.shutdownContext(ctx)
+	defer cancelShutdown()
+
+	shutdownErr := a.runShutdownTasks(shutdownCtx)
+	if shutdownErr == nil {
+		return err
+	}
+
+	log.ErrorContext(ctx, "error in application shutdown", "error", shutdownErr)
+	return errors.Join(err, shutdownErr)
+}
+
+// stopServices stops running services one by one in reverse dependency order.
+func (a *Application) stopServices(ctx context.Context, serviceOrder []string, states map[string]*serviceState) error {
+	for _, serviceName := range slices.Backward(serviceOrder) {
+		a.health.StopService(serviceName)
+		states[serviceName].cancel()
+
+		select {
+		case <-states[serviceName].done:
+		case <-ctx.Done():
+			return fmt.Errorf("%w: service %s did not stop in time", ErrShutdownTimeout, serviceName)
 		}
 	}
 
-	var wg sync.WaitGroup
+	return nil
+}
 
-	for serviceName, service := range a.services {
-		wg.Add(1)
+// runShutdownTasks runs shutdown tasks in reverse registration order.
+// Errors and panics in shutdown tasks are logged and do not prevent other tasks from running.
+func (a *Application) runShutdownTasks(ctx context.Context) error {
+	done := make(chan struct{})
 
-		serviceCtx := context.WithValue(ctx, log.ServiceNameKey, serviceName)
+	go func() {
+		defer close(done)
 
-		go func() {
-			defer wg.Done()
-			defer func() {
-				if r := recover(); r != nil {
-					log.ErrorContext(serviceCtx, "service panicked", string(log.ServiceNameKey), serviceName, "panic", r)
-				}
-			}()
+		for i, task := range slices.Backward(a.shutdownTasks) {
+			log.InfoContext(ctx, "running shutdown task", "task", task.config.Name, "index", i)
 
-			log.InfoContext(ctx, "starting service", string(log.ServiceNameKey), serviceName)
-			a.health.StartService(serviceName)
+			taskCtx := context.WithValue(ctx, log.ShutdownTaskKey, task.config.Name)
 
-			err := service.Run(serviceCtx)
+			err := runShutdownTask(taskCtx, task)
 			if err != nil {
-				a.health.FailService(serviceName, err)
-				log.ErrorContext(ctx, "error in service", string(log.ServiceNameKey), serviceName, "error", err)
+				log.ErrorContext(taskCtx, "error in shutdown task", "error", err, "task", task.config.Name)
+			}
+		}
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%w: shutdown tasks did not finish in time", ErrShutdownTimeout)
+	}
+}
+
+// startService waits for all dependencies of the service to become ready and runs it.
+// The service fails without being started if any of its dependencies stops before becoming ready.
+func (a *Application) startService(ctx context.Context, serviceName string, states map[string]*serviceState, failApplication func(error)) {
+	state := states[serviceName]
+	defer close(state.done)
+
+	for _, dep := range a.services[serviceName].config.dependsOn {
+		select {
+		case <-states[dep].ready:
+		case <-states[dep].done:
+			err := fmt.Errorf("%w: %s", ErrServiceDependencyStopped, dep)
+			log.ErrorContext(ctx, "service dependency stopped before becoming ready", string(log.ServiceNameKey), serviceName, "dependency", dep)
+			a.health.FailService(serviceName, err)
+			a.emit(ctx, ServiceFailed{EventTime: newEventTime(), Name: serviceName, Err: err})
+
+			if a.services[serviceName].config.critical {
+				failApplication(&ErrServiceFailed{serviceName: serviceName, err: err})
+			}
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	a.superviseService(ctx, serviceName, state, failApplication)
+}
+
+// superviseService runs the service and restarts it according to its restart policy.
+// If a critical service fails and is not restarted, the whole application is stopped.
+func (a *Application) superviseService(ctx context.Context, serviceName string, state *serviceState, failApplication func(error)) {
+	config := a.services[serviceName].config
+
+	for restarts := 0; ; restarts++ {
+		err := a.runService(state.startRun(ctx), serviceName, state)
+		restartRequested := state.finishRun()
+
+		// Service was stopped by the application
+		if ctx.Err() != nil {
+			return
+		}
+
+		// Restarts requested with RestartService don't count towards the restart policy attempts
+		if restartRequested {
+			log.InfoContext(ctx, "restarting service on request", string(log.ServiceNameKey), serviceName)
+			a.health.RestartService(serviceName)
+			restarts--
+			continue
+		}
+
+		if !config.restartPolicy.shouldRestart(err, restarts) {
+			if err != nil && config.critical {
+				log.ErrorContext(ctx, "critical service failed, stopping application", string(log.ServiceNameKey), serviceName, "error", err)
+				failApplication(&ErrServiceFailed{serviceName: serviceName, err: err})
+			}
+			return
+		}
+
+		delay := config.restartPolicy.backoff(restarts)
+		log.WarnContext(ctx, "restarting service", string(log.ServiceNameKey), serviceName, "restart", restarts+1, "delay", delay)
+		a.health.RestartService(serviceName)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			a.health.FinishService(serviceName)
+			return
+		}
+	}
+}
+
+// runService runs the service once. Panics are recovered and returned as ErrServicePanicked.
+func (a *Application) runService(ctx context.Context, serviceName string, state *serviceState) (err error) {
+	runner := a.services[serviceName].runner
+
+	stopped := make(chan struct{})
+	defer close(stopped)
+	defer func() {
+		if r := recover(); r != nil {
+			panicErr := &ErrServicePanicked{Value: r, Stack: debug.Stack()}
+			log.ErrorContext(ctx, "service panicked", string(log.ServiceNameKey), serviceName, "panic", r, "stack", string(panicErr.Stack))
+			a.health.FailService(serviceName, panicErr)
+			a.emit(ctx, ServicePanicked{EventTime: newEventTime(), Name: serviceName, Value: r, Stack: panicErr.Stack})
+			err = panicErr
+		}
+	}()
+
+	log.InfoContext(ctx, "starting service", string(log.ServiceNameKey), serviceName)
+	a.health.StartService(serviceName)
+	a.emit(ctx, ServiceStarted{EventTime: newEventTime(), Name: serviceName})
+
+	if readier, ok := runner.(Readier); ok {
+		go func() {
+			select {
+			case <-readier.Ready():
+				a.markServiceReady(serviceName, state)
+			case <-stopped:
 			}
 		}()
+	} else {
+		a.markServiceReady(serviceName, state)
 	}
 
-	a.health.StartedAt = time.Now()
+	err = runner.Run(ctx)
 
-	wg.Wait()
+	// Errors caused by the application stopping the service are not failures
+	if err == nil || (ctx.Err() != nil && errors.Is(err, context.Canceled)) {
+		a.health.FinishService(serviceName)
+		a.emit(ctx, ServiceStopped{EventTime: newEventTime(), Name: serviceName})
+	} else {
+		a.health.FailService(serviceName, err)
+		log.ErrorContext(ctx, "error in service", string(log.ServiceNameKey), serviceName, "error", err)
+		a.emit(ctx, ServiceFailed{EventTime: newEventTime(), Name: serviceName, Err: err})
+	}
 
-	return nil
+	return err
+}
+
+func (a *Application) markServiceReady(serviceName string, state *serviceState) {
+	state.markReady()
+	a.health.ReadyService(serviceName)
 }
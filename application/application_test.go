@@ -0,0 +1,228 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/platforma-dev/platforma/application"
+	"github.com/platforma-dev/platforma/config"
+)
+
+func TestRegisterConfig(t *testing.T) {
+	t.Parallel()
+
+	type appConfig struct {
+		DSN string `config:"dsn" required:"true"`
+	}
+
+	t.Run("config is loaded before startup tasks", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := config.New[appConfig](config.FromFlags([]string{"-dsn=postgres://localhost"}))
+
+		var dsn string
+		app := application.New()
+		app.RegisterConfig("app", cfg)
+		app.OnStartFunc(func(_ context.Context) error {
+			dsn = cfg.Value().DSN
+			return nil
+		}, application.StartupTaskConfig{Name: "read config"})
+
+		err := app.Run(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if dsn != "postgres://localhost" {
+			t.Fatalf("expected dsn to be loaded, got %q", dsn)
+		}
+	})
+
+	t.Run("invalid config fails the application", func(t *testing.T) {
+		t.Parallel()
+
+		started := false
+		app := application.New()
+		app.RegisterConfig("app", config.New[appConfig]())
+		app.RegisterService("svc", application.RunnerFunc(func(_ context.Context) error {
+			started = true
+			return nil
+		}))
+
+		err := app.Run(context.Background())
+
+		var loadErr *application.ErrConfigLoadFailed
+		if !errors.As(err, &loadErr) {
+			t.Fatalf("expected ErrConfigLoadFailed, got %v", err)
+		}
+
+		if !errors.Is(err, config.ErrRequired) {
+			t.Fatalf("expected ErrRequired, got %v", err)
+		}
+
+		if started {
+			t.Fatal("expected service not to start")
+		}
+	})
+}
+
+func TestServiceDependencies(t *testing.T) {
+	t.Parallel()
+
+	t.Run("start in dependency order and stop in reverse", func(t *testing.T) {
+		t.Parallel()
+
+		events := &eventLog{}
+		app := application.New()
+
+		app.RegisterService("api", &mockService{name: "api", events: events}, application.DependsOn("processor"))
+		app.RegisterService("processor", &mockService{name: "processor", events: events, readyAfter: 20 * time.Millisecond}, application.DependsOn("db"))
+		app.RegisterService("db", &mockService{name: "db", events: events})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			cancel()
+		}()
+
+		err := app.Run(ctx)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		expected := []string{
+			"start db", "start processor", "ready processor", "start api",
+			"stop api", "stop processor", "stop db",
+		}
+		if got := events.get(); !slices.Equal(got, expected) {
+			t.Fatalf("expected events %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("dependency cycle", func(t *testing.T) {
+		t.Parallel()
+
+		app := application.New()
+		app.RegisterService("a", &mockService{}, application.DependsOn("b"))
+		app.RegisterService("b", &mockService{}, application.DependsOn("c"))
+		app.RegisterService("c", &mockService{}, application.DependsOn("a"))
+
+		err := app.Run(context.Background())
+		if !errors.Is(err, application.ErrServiceDependencyCycle) {
+			t.Fatalf("expected dependency cycle error, got %v", err)
+		}
+	})
+
+	t.Run("unknown dependency", func(t *testing.T) {
+		t.Parallel()
+
+		app := application.New()
+		app.RegisterService("a", &mockService{}, application.DependsOn("missing"))
+
+		err := app.Run(context.Background())
+		if !errors.Is(err, application.ErrUnknownServiceDependency) {
+			t.Fatalf("expected unknown dependency error, got %v", err)
+		}
+	})
+
+	t.Run("dependent is not started when dependency stops before ready", func(t *testing.T) {
+		t.Parallel()
+
+		events := &eventLog{}
+		app := application.New()
+
+		app.RegisterService("api", &mockService{name: "api", events: events}, application.DependsOn("processor"))
+		app.RegisterService("processor", &mockService{name: "processor", events: events, readyAfter: time.Hour, runErr: errors.New("boom")})
+
+		err := app.Run(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if got := events.get(); slices.Contains(got, "start api") {
+			t.Fatalf("expected api not to start, got events %v", got)
+		}
+
+		api, ok := app.Health(context.Background()).Services["api"]
+		if !ok || api.Status != application.ServiceStatusFailed || !strings.Contains(api.Error, "processor") {
+			t.Fatalf("expected api to fail because of processor, got %+v", api)
+		}
+	})
+
+	t.Run("critical dependent fails application when dependency stops before ready", func(t *testing.T) {
+		t.Parallel()
+
+		app := application.New()
+
+		app.RegisterService("api", &mockService{name: "api"}, application.DependsOn("processor"), application.Critical())
+		app.RegisterService("processor", &mockService{name: "processor", readyAfter: time.Hour, runErr: errors.New("boom")})
+
+		err := app.Run(context.Background())
+		if !errors.Is(err, application.ErrServiceDependencyStopped) {
+			t.Fatalf("expected ErrServiceDependencyStopped, got %v", err)
+		}
+	})
+}
+
+type eventLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *eventLog) add(event string) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event)
+}
+
+func (l *eventLog) get() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Clone(l.events)
+}
+
+type mockService struct {
+	name       string
+	events     *eventLog
+	readyAfter time.Duration
+	runErr     error
+	readyOnce  sync.Once
+	ready      chan struct{}
+}
+
+func (s *mockService) Ready() <-chan struct{} {
+	return s.readyChan()
+}
+
+func (s *mockService) readyChan() chan struct{} {
+	s.readyOnce.Do(func() { s.ready = make(chan struct{}) })
+	return s.ready
+}
+
+func (s *mockService) Run(ctx context.Context) error {
+	s.events.add("start " + s.name)
+
+	if s.runErr != nil {
+		return s.runErr
+	}
+
+	if s.readyAfter > 0 {
+		time.Sleep(s.readyAfter)
+		s.events.add("ready " + s.name)
+	}
+	close(s.readyChan())
+
+	<-ctx.Done()
+	s.events.add("stop " + s.name)
+
+	return nil
+}
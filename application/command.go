@@ -0,0 +1,350 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/platforma-dev/platforma/log"
+)
+
+// Exit codes returned by ExitCode.
+const (
+	ExitCodeSuccess = 0
+	ExitCodeFailure = 1
+	ExitCodeUsage   = 2
+)
+
+const defaultCommand = "serve"
+
+// ErrUnknownCommand is returned by Execute when no command with the given name is registered.
+var ErrUnknownCommand = errors.New("unknown command")
+
+// ErrUsage is returned by commands called with invalid arguments.
+// Execute prints usage of the command when it gets this error.
+var ErrUsage = errors.New("invalid arguments")
+
+// ErrUnknownJob is returned by the run-job command when no service with the given name implements Job.
+var ErrUnknownJob = errors.New("unknown job")
+
+// ErrCommandFailed is returned by Execute when a command returns an error.
+type ErrCommandFailed struct {
+	commandName string
+	err         error
+}
+
+// Error returns the formatted error message for ErrCommandFailed.
+func (e *ErrCommandFailed) Error() string {
+	return fmt.Sprintf("command %s failed: %v", e.commandName, e.err)
+}
+
+// Unwrap returns the underlying error for ErrCommandFailed.
+func (e *ErrCommandFailed) Unwrap() error {
+	return e.err
+}
+
+// CommandName returns the name of the failed command.
+func (e *ErrCommandFailed) CommandName() string {
+	return e.commandName
+}
+
+// Command is a one-off task executed by Execute instead of long-lived services.
+type Command interface {
+	// Run executes the command with the arguments following the command name.
+	Run(ctx context.Context, args []string) error
+}
+
+// CommandFunc is a function type that implements the Command interface.
+type CommandFunc func(ctx context.Context, args []string) error
+
+// Run executes the CommandFunc with the given context and arguments.
+func (f CommandFunc) Run(ctx context.Context, args []string) error {
+	return f(ctx, args)
+}
+
+// CommandConfig contains configuration options for a command.
+type CommandConfig struct {
+	Description  string   // Short description shown in the list of commands
+	Usage        string   // Synopsis of the command arguments, e.g. "<name>"
+	Databases    []string // Names of databases migrated before the command runs
+	StartupTasks bool     // Whether startup tasks run before the command and shutdown tasks after it
+}
+
+// Job is implemented by services that can be run once by hand with the run-job command, e.g. scheduler.Scheduler.
+type Job interface {
+	// RunOnce runs the job once and returns its error.
+	RunOnce(context.Context) error
+}
+
+type command struct {
+	command Command
+	config  CommandConfig
+	bare    bool // Command initializes the application by itself
+}
+
+// CommandInfo describes a command available to Execute.
+type CommandInfo struct {
+	Name        string
+	Description string
+	Usage       string
+}
+
+// RegisterCommand adds a named command to the application.
+// Registering a command with the name of a built-in command replaces it.
+func (a *Application) RegisterCommand(commandName string, cmd Command, config CommandConfig) {
+	a.commands[commandName] = &command{command: cmd, config: config}
+}
+
+// RegisterCommandFunc adds a named command implemented by the given function.
+func (a *Application) RegisterCommandFunc(commandName string, cmd CommandFunc, config CommandConfig) {
+	a.RegisterCommand(commandName, cmd, config)
+}
+
+// Commands returns all commands available to Execute ordered by name, including built-in ones.
+func (a *Application) Commands() []CommandInfo {
+	commands := a.availableCommands()
+
+	infos := make([]CommandInfo, 0, len(commands))
+	for _, commandName := range slices.Sorted(maps.Keys(commands)) {
+		config := commands[commandName].config
+		infos = append(infos, CommandInfo{Name: commandName, Description: config.Description, Usage: config.Usage})
+	}
+
+	return infos
+}
+
+// Execute runs the command named by the first argument with the rest of the arguments.
+// Without arguments the serve command is executed.
+//
+// Arguments are split as [application flags] [command] [command arguments]. Application flags,
+// e.g. -port=9090, are passed to registered configurations that load flags, see config.Config.SetFlags.
+// Before the command name they have to be in the -name=value form, as a separate value would be taken
+// for the command name. The serve command takes application flags after its name too, e.g. serve -port 9090.
+//
+// Registered configurations are loaded before every command. Only databases listed in the command
+// configuration are migrated and startup tasks run only if the command asks for them. Services are not started.
+//
+// Built-in commands:
+//   - serve runs the application with all its services, see Run.
+//   - migrate [-database name] [-dry-run] [-allow-drift] [status|up|down [count]|to <migration>|redo] shows, applies
+//     or rolls back migrations of the given database or all registered databases, see Database.MigrateUp.
+//   - run-job <name> migrates all databases and runs the service implementing Job once, e.g. a scheduler.
+//   - help prints the list of available commands.
+func (a *Application) Execute(ctx context.Context, args []string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	flags, args := splitFlags(args)
+	a.setConfigFlags(flags)
+
+	commandName := defaultCommand
+	if len(args) > 0 {
+		commandName, args = args[0], args[1:]
+	}
+
+	commands := a.availableCommands()
+
+	cmd, ok := commands[commandName]
+	if !ok {
+		a.printCommands()
+		return fmt.Errorf("%w: %s", ErrUnknownCommand, commandName)
+	}
+
+	err := a.runCommand(ctx, commandName, cmd, args)
+	if err != nil {
+		if errors.Is(err, ErrUsage) {
+			fmt.Fprintf(a.output, "%v\nusage: %s %s\n", err, commandName, cmd.config.Usage)
+		}
+		return &ErrCommandFailed{commandName: commandName, err: err}
+	}
+
+	return nil
+}
+
+// Main executes the command given in the process arguments and exits with the code matching its result.
+func (a *Application) Main(ctx context.Context) {
+	err := a.Execute(ctx, os.Args[1:])
+	if err != nil {
+		log.ErrorContext(ctx, "error in command", "error", err)
+	}
+
+	os.Exit(ExitCode(err))
+}
+
+// ExitCode returns the process exit code for the error returned by Execute.
+// Errors implementing ExitCode() int define their own exit code, invalid usage is reported
+// with ExitCodeUsage and all other errors with ExitCodeFailure.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitCodeSuccess
+	}
+
+	var coder interface{ ExitCode() int }
+	if errors.As(err, &coder) {
+		return coder.ExitCode()
+	}
+
+	if errors.Is(err, ErrUsage) || errors.Is(err, ErrUnknownCommand) {
+		return ExitCodeUsage
+	}
+
+	return ExitCodeFailure
+}
+
+// runCommand initializes parts of the application required by the command and runs it.
+func (a *Application) runCommand(ctx context.Context, commandName string, cmd *command, args []string) error {
+	if cmd.bare {
+		return cmd.command.Run(ctx, args)
+	}
+
+	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt, os.Kill)
+	defer cancel()
+
+	ctx = context.WithValue(ctx, log.CommandKey, commandName)
+
+	err := a.startCommand(ctx, cmd)
+	if err == nil {
+		log.InfoContext(ctx, "running command")
+		err = cmd.command.Run(ctx, args)
+	}
+
+	// Shutdown tasks come with startup tasks and run even if the command failed to start
+	if cmd.config.StartupTasks {
+		err = a.shutdown(ctx, err)
+	}
+
+	return err
+}
+
+// startCommand loads configs, resolves domains, migrates databases of the command and runs startup tasks if enabled.
+func (a *Application) startCommand(ctx context.Context, cmd *command) error {
+	err := a.loadConfigs(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = a.resolveDomains(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = a.migrateDatabases(ctx, cmd.config.Databases)
+	if err != nil {
+		return err
+	}
+
+	if cmd.config.StartupTasks {
+		return a.runStartupTasks(ctx)
+	}
+
+	return nil
+}
+
+// availableCommands returns built-in commands merged with the registered ones.
+func (a *Application) availableCommands() map[string]*command {
+	allDatabases := slices.Sorted(maps.Keys(a.databases))
+
+	commands := map[string]*command{
+		"serve": {
+			command: CommandFunc(a.serveCommand),
+			config:  CommandConfig{Description: "Run the application with all services"},
+			bare:    true,
+		},
+		"migrate": {
+			command: CommandFunc(a.migrateCommand),
+			config:  CommandConfig{Description: "Show, apply or roll back database migrations", Usage: migrateUsage},
+		},
+		"run-job": {
+			command: CommandFunc(a.runJobCommand),
+			config:  CommandConfig{Description: "Run a job once", Usage: "<name>", Databases: allDatabases},
+		},
+		"help": {
+			command: CommandFunc(a.helpCommand),
+			config:  CommandConfig{Description: "Show available commands"},
+			bare:    true,
+		},
+	}
+
+	maps.Copy(commands, a.commands)
+
+	return commands
+}
+
+func (a *Application) serveCommand(ctx context.Context, args []string) error {
+	if len(args) > 0 {
+		if !isFlag(args[0]) {
+			return fmt.Errorf("%w: unexpected arguments %v", ErrUsage, args)
+		}
+
+		a.setConfigFlags(append(slices.Clone(a.flags), args...))
+	}
+
+	return a.Run(ctx)
+}
+
+// splitFlags splits application flags preceding the command name from the rest of the arguments.
+func splitFlags(args []string) ([]string, []string) {
+	i := 0
+	for i < len(args) && isFlag(args[i]) {
+		i++
+	}
+	return args[:i], args[i:]
+}
+
+func isFlag(arg string) bool {
+	return len(arg) > 1 && arg[0] == '-'
+}
+
+// setConfigFlags passes the application flags to registered configurations that load flags.
+func (a *Application) setConfigFlags(flags []string) {
+	a.flags = flags
+	for _, config := range a.configs {
+		if flagsConfig, ok := config.(interface{ SetFlags(args []string) }); ok {
+			flagsConfig.SetFlags(flags)
+		}
+	}
+}
+
+func (a *Application) runJobCommand(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%w: expected job name", ErrUsage)
+	}
+
+	jobName := args[0]
+
+	service, ok := a.services[jobName]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownJob, jobName)
+	}
+
+	job, ok := service.runner.(Job)
+	if !ok {
+		return fmt.Errorf("%w: service %s can't be run as a job", ErrUnknownJob, jobName)
+	}
+
+	return job.RunOnce(context.WithValue(ctx, log.ServiceNameKey, jobName))
+}
+
+func (a *Application) helpCommand(_ context.Context, _ []string) error {
+	a.printCommands()
+	return nil
+}
+
+// printCommands writes the list of available commands to the application output.
+func (a *Application) printCommands() {
+	w := tabwriter.NewWriter(a.output, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "Commands:")
+	for _, info := range a.Commands() {
+		fmt.Fprintf(w, "  %s\t%s\n", strings.TrimSpace(info.Name+" "+info.Usage), info.Description)
+	}
+
+	_ = w.Flush()
+}
@@ -0,0 +1,349 @@
+package application_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/platforma-dev/platforma/application"
+	"github.com/platforma-dev/platforma/config"
+)
+
+type countingConfig struct {
+	loads atomic.Int32
+}
+
+func (c *countingConfig) Load(_ context.Context) error {
+	c.loads.Add(1)
+	return nil
+}
+
+type mockJob struct {
+	runs atomic.Int32
+	err  error
+}
+
+func (j *mockJob) Run(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+func (j *mockJob) RunOnce(_ context.Context) error {
+	j.runs.Add(1)
+	return j.err
+}
+
+type exitCodeError struct{}
+
+func (exitCodeError) Error() string { return "nothing to do" }
+func (exitCodeError) ExitCode() int { return 3 }
+
+func TestExecute(t *testing.T) {
+	t.Parallel()
+
+	t.Run("custom command", func(t *testing.T) {
+		t.Parallel()
+
+		config := &countingConfig{}
+		var startupTasks, services atomic.Int32
+
+		app := application.New()
+		app.RegisterConfig("app", config)
+		app.OnStartFunc(func(_ context.Context) error {
+			startupTasks.Add(1)
+			return nil
+		}, application.StartupTaskConfig{Name: "warm up"})
+		app.RegisterService("api", application.RunnerFunc(func(_ context.Context) error {
+			services.Add(1)
+			return nil
+		}))
+
+		var gotArgs []string
+		app.RegisterCommandFunc("backfill", func(_ context.Context, args []string) error {
+			gotArgs = args
+			return nil
+		}, application.CommandConfig{Description: "Backfill data"})
+
+		err := app.Execute(context.Background(), []string{"backfill", "--since", "2024-01-01"})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if !slices.Equal(gotArgs, []string{"--since", "2024-01-01"}) {
+			t.Fatalf("expected command arguments, got %v", gotArgs)
+		}
+
+		if config.loads.Load() != 1 {
+			t.Fatalf("expected config to be loaded once, got %d", config.loads.Load())
+		}
+
+		if startupTasks.Load() != 0 || services.Load() != 0 {
+			t.Fatalf("expected no startup tasks and services to run, got %d tasks and %d services", startupTasks.Load(), services.Load())
+		}
+	})
+
+	t.Run("command with startup tasks", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []string
+		app := application.New()
+		app.OnStartFunc(func(_ context.Context) error {
+			calls = append(calls, "start")
+			return nil
+		}, application.StartupTaskConfig{Name: "start"})
+		app.OnStopFunc(func(_ context.Context) error {
+			calls = append(calls, "stop")
+			return nil
+		}, application.ShutdownTaskConfig{Name: "stop"})
+		app.RegisterCommandFunc("seed", func(_ context.Context, _ []string) error {
+			calls = append(calls, "seed")
+			return nil
+		}, application.CommandConfig{StartupTasks: true})
+
+		err := app.Execute(context.Background(), []string{"seed"})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if !slices.Equal(calls, []string{"start", "seed", "stop"}) {
+			t.Fatalf("expected startup task, command and shutdown task, got %v", calls)
+		}
+	})
+
+	t.Run("serve by default", func(t *testing.T) {
+		t.Parallel()
+
+		var services atomic.Int32
+		app := application.New()
+		app.RegisterService("api", application.RunnerFunc(func(_ context.Context) error {
+			services.Add(1)
+			return nil
+		}))
+
+		err := app.Execute(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if services.Load() != 1 {
+			t.Fatalf("expected service to run, got %d runs", services.Load())
+		}
+	})
+
+	t.Run("application flags", func(t *testing.T) {
+		t.Parallel()
+
+		type appConfig struct {
+			Port int `default:"8080"`
+		}
+
+		for _, args := range [][]string{
+			{"-port=9090"},
+			{"-port=9090", "serve"},
+			{"serve", "-port", "9090"},
+			{"serve", "-port=9090"},
+		} {
+			cfg := config.New[appConfig](config.FromFlags(nil))
+
+			app := application.New()
+			app.RegisterConfig("app", cfg)
+			app.RegisterService("api", application.RunnerFunc(func(_ context.Context) error {
+				return nil
+			}))
+
+			err := app.Execute(context.Background(), args)
+			if err != nil {
+				t.Fatalf("expected no error for %v, got %v", args, err)
+			}
+
+			if cfg.Value().Port != 9090 {
+				t.Fatalf("expected port from flags %v, got %d", args, cfg.Value().Port)
+			}
+		}
+
+		cfg := config.New[appConfig](config.FromFlags(nil))
+
+		app := application.New()
+		app.RegisterConfig("app", cfg)
+
+		var gotArgs []string
+		app.RegisterCommandFunc("backfill", func(_ context.Context, args []string) error {
+			gotArgs = args
+			return nil
+		}, application.CommandConfig{})
+
+		err := app.Execute(context.Background(), []string{"-port=9090", "backfill", "-since", "2024-01-01"})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if cfg.Value().Port != 9090 || !slices.Equal(gotArgs, []string{"-since", "2024-01-01"}) {
+			t.Fatalf("expected application flag and command arguments, got port %d and %v", cfg.Value().Port, gotArgs)
+		}
+	})
+
+	t.Run("application flags of several configs", func(t *testing.T) {
+		t.Parallel()
+
+		type appConfig struct {
+			Port int `default:"8080"`
+		}
+
+		type dbConfig struct {
+			DSN string `config:"dsn"`
+		}
+
+		appCfg := config.New[appConfig](config.FromFlags(nil))
+		dbCfg := config.New[dbConfig](config.FromFlags(nil))
+
+		app := application.New()
+		app.RegisterConfig("app", appCfg)
+		app.RegisterConfig("db", dbCfg)
+		app.RegisterService("api", application.RunnerFunc(func(_ context.Context) error {
+			return nil
+		}))
+
+		err := app.Execute(context.Background(), []string{"-port=9090", "serve", "-dsn", "postgres://localhost"})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if appCfg.Value().Port != 9090 || dbCfg.Value().DSN != "postgres://localhost" {
+			t.Fatalf("expected every config to get its own flags, got port %d and dsn %q", appCfg.Value().Port, dbCfg.Value().DSN)
+		}
+	})
+
+	t.Run("unknown command", func(t *testing.T) {
+		t.Parallel()
+
+		var output bytes.Buffer
+		app := application.New(application.WithOutput(&output))
+		app.RegisterCommandFunc("backfill", func(_ context.Context, _ []string) error {
+			return nil
+		}, application.CommandConfig{Description: "Backfill data"})
+
+		err := app.Execute(context.Background(), []string{"backfil"})
+		if !errors.Is(err, application.ErrUnknownCommand) {
+			t.Fatalf("expected ErrUnknownCommand, got %v", err)
+		}
+
+		if application.ExitCode(err) != application.ExitCodeUsage {
+			t.Fatalf("expected usage exit code, got %d", application.ExitCode(err))
+		}
+
+		for _, expected := range []string{"backfill", "Backfill data", "migrate [-database name]", "run-job <name>", "serve"} {
+			if !strings.Contains(output.String(), expected) {
+				t.Fatalf("expected list of commands to contain %q, got:\n%s", expected, output.String())
+			}
+		}
+	})
+
+	t.Run("invalid usage", func(t *testing.T) {
+		t.Parallel()
+
+		var output bytes.Buffer
+		app := application.New(application.WithOutput(&output))
+
+		err := app.Execute(context.Background(), []string{"run-job"})
+		if !errors.Is(err, application.ErrUsage) {
+			t.Fatalf("expected ErrUsage, got %v", err)
+		}
+
+		if application.ExitCode(err) != application.ExitCodeUsage {
+			t.Fatalf("expected usage exit code, got %d", application.ExitCode(err))
+		}
+
+		if !strings.Contains(output.String(), "usage: run-job <name>") {
+			t.Fatalf("expected command usage, got %q", output.String())
+		}
+	})
+
+	t.Run("invalid migrate usage", func(t *testing.T) {
+		t.Parallel()
+
+		app := application.New(application.WithOutput(&bytes.Buffer{}))
+
+		for _, args := range [][]string{
+			{"migrate", "sideways"},
+			{"migrate", "down", "zero"},
+			{"migrate", "to"},
+			{"migrate", "status", "extra"},
+			{"migrate", "-database", "missing"},
+		} {
+			err := app.Execute(context.Background(), args)
+			if !errors.Is(err, application.ErrUsage) {
+				t.Fatalf("expected ErrUsage for %v, got %v", args, err)
+			}
+		}
+	})
+
+	t.Run("run job", func(t *testing.T) {
+		t.Parallel()
+
+		job := &mockJob{}
+		app := application.New()
+		app.RegisterService("cleanup", job)
+
+		err := app.Execute(context.Background(), []string{"run-job", "cleanup"})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if job.runs.Load() != 1 {
+			t.Fatalf("expected job to run once, got %d runs", job.runs.Load())
+		}
+	})
+
+	t.Run("failed job", func(t *testing.T) {
+		t.Parallel()
+
+		jobErr := errors.New("cleanup failed")
+		app := application.New()
+		app.RegisterService("cleanup", &mockJob{err: jobErr})
+
+		err := app.Execute(context.Background(), []string{"run-job", "cleanup"})
+
+		var commandErr *application.ErrCommandFailed
+		if !errors.As(err, &commandErr) || commandErr.CommandName() != "run-job" || !errors.Is(err, jobErr) {
+			t.Fatalf("expected ErrCommandFailed wrapping job error, got %v", err)
+		}
+
+		if application.ExitCode(err) != application.ExitCodeFailure {
+			t.Fatalf("expected failure exit code, got %d", application.ExitCode(err))
+		}
+	})
+
+	t.Run("unknown job", func(t *testing.T) {
+		t.Parallel()
+
+		app := application.New()
+		app.RegisterService("api", application.RunnerFunc(func(_ context.Context) error {
+			return nil
+		}))
+
+		for _, jobName := range []string{"api", "missing"} {
+			err := app.Execute(context.Background(), []string{"run-job", jobName})
+			if !errors.Is(err, application.ErrUnknownJob) {
+				t.Fatalf("expected ErrUnknownJob for %s, got %v", jobName, err)
+			}
+		}
+	})
+
+	t.Run("custom exit code", func(t *testing.T) {
+		t.Parallel()
+
+		app := application.New()
+		app.RegisterCommandFunc("check", func(_ context.Context, _ []string) error {
+			return exitCodeError{}
+		}, application.CommandConfig{})
+
+		err := app.Execute(context.Background(), []string{"check"})
+		if application.ExitCode(err) != 3 {
+			t.Fatalf("expected exit code defined by error, got %d", application.ExitCode(err))
+		}
+	})
+}
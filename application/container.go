@@ -0,0 +1,238 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ErrProviderNotFound is returned when no provider is registered for the requested dependency.
+var ErrProviderNotFound = errors.New("provider not found")
+
+// ErrAmbiguousProvider is returned when an interface dependency is implemented by more than one provider.
+var ErrAmbiguousProvider = errors.New("ambiguous provider")
+
+// ErrDependencyCycle is returned when a dependency depends on itself, directly or through other dependencies.
+var ErrDependencyCycle = errors.New("dependency cycle")
+
+// ErrInvalidInjectTarget is returned by Inject when the target is not a pointer to a struct.
+var ErrInvalidInjectTarget = errors.New("inject target must be a pointer to a struct")
+
+// providerKey identifies a dependency by its type and optional name.
+type providerKey struct {
+	typ  reflect.Type
+	name string
+}
+
+func (k providerKey) String() string {
+	if k.name != "" {
+		return fmt.Sprintf("%s %q", k.typ, k.name)
+	}
+	return k.typ.String()
+}
+
+// provider builds a dependency once and caches the result.
+type provider struct {
+	provide func(*Container) (any, error)
+	once    sync.Once
+	value   any
+	err     error
+}
+
+// dependency is an entry of the chain of dependencies being resolved.
+type dependency struct {
+	key      providerKey
+	provider *provider
+}
+
+type registry struct {
+	mu        sync.RWMutex
+	providers map[providerKey]*provider
+}
+
+// Container holds providers of dependencies and resolves them lazily.
+// Every provider is called at most once and its result is shared by all dependents.
+//
+// Providers receive the container to resolve their own dependencies.
+// Containers passed to providers track the chain of dependencies being resolved to detect cycles.
+type Container struct {
+	registry *registry
+	path     []dependency // Dependencies being resolved, outermost first
+}
+
+// NewContainer creates an empty Container.
+func NewContainer() *Container {
+	return &Container{registry: &registry{providers: make(map[providerKey]*provider)}}
+}
+
+// Provide registers a provider of dependencies of type T.
+// Registering a provider for the same type again replaces the previous one.
+func Provide[T any](c *Container, provide func(*Container) (T, error)) {
+	ProvideNamed(c, "", provide)
+}
+
+// ProvideNamed registers a provider of the named dependency of type T.
+// Named dependencies allow several values of the same type, e.g. multiple databases.
+func ProvideNamed[T any](c *Container, name string, provide func(*Container) (T, error)) {
+	key := providerKey{typ: reflect.TypeFor[T](), name: name}
+
+	c.registry.mu.Lock()
+	defer c.registry.mu.Unlock()
+
+	c.registry.providers[key] = &provider{provide: func(c *Container) (any, error) {
+		return provide(c)
+	}}
+}
+
+// ProvideValue registers an already built dependency of type T.
+func ProvideValue[T any](c *Container, value T) {
+	Provide(c, func(*Container) (T, error) {
+		return value, nil
+	})
+}
+
+// Resolve returns the dependency of type T, building it and its dependencies on first use.
+// If T is an interface without a provider of its own, the only provider of a type implementing T is used.
+func Resolve[T any](c *Container) (T, error) {
+	return ResolveNamed[T](c, "")
+}
+
+// ResolveNamed returns the named dependency of type T, building it and its dependencies on first use.
+func ResolveNamed[T any](c *Container, name string) (T, error) {
+	value, err := c.resolve(providerKey{typ: reflect.TypeFor[T](), name: name})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	result, _ := value.(T)
+	return result, nil
+}
+
+// MustResolve is like Resolve but panics if the dependency can't be resolved.
+// It simplifies wiring in main where there is no way to recover from a missing dependency.
+func MustResolve[T any](c *Container) T {
+	value, err := Resolve[T](c)
+	if err != nil {
+		panic(err)
+	}
+
+	return value
+}
+
+// Inject resolves dependencies into fields of the struct pointed to by target.
+// Only fields with the `inject` tag are set. The tag value is the name of the dependency,
+// add ",optional" to leave the field unset when there is no provider:
+//
+//	var deps struct {
+//		DB      *sqlx.DB        `inject:""`
+//		Replica *sqlx.DB        `inject:"replica,optional"`
+//		Cleanup cleanupEnqueuer `inject:",optional"`
+//	}
+func Inject(c *Container, target any) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: got %T", ErrInvalidInjectTarget, target)
+	}
+	v = v.Elem()
+
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+
+		tag, ok := field.Tag.Lookup("inject")
+		if !ok {
+			continue
+		}
+
+		name, option, _ := strings.Cut(tag, ",")
+		key := providerKey{typ: field.Type, name: name}
+
+		if option == "optional" {
+			if _, err := c.lookup(key); errors.Is(err, ErrProviderNotFound) {
+				continue
+			}
+		}
+
+		value, err := c.resolve(key)
+		if err != nil {
+			return fmt.Errorf("failed to inject %s: %w", field.Name, err)
+		}
+
+		if value != nil {
+			v.Field(i).Set(reflect.ValueOf(value))
+		}
+	}
+
+	return nil
+}
+
+func (c *Container) resolve(key providerKey) (any, error) {
+	p, err := c.lookup(key)
+	if err != nil {
+		return nil, err
+	}
+
+	path := append(slices.Clone(c.path), dependency{key: key, provider: p})
+
+	// Interface dependencies may resolve to the provider of another key, so cycles are detected by provider
+	if slices.ContainsFunc(c.path, func(d dependency) bool { return d.provider == p }) {
+		return nil, fmt.Errorf("%w: %s", ErrDependencyCycle, formatDependencyPath(path))
+	}
+
+	p.once.Do(func() {
+		p.value, p.err = p.provide(&Container{registry: c.registry, path: path})
+		if p.err != nil {
+			p.err = fmt.Errorf("failed to provide %s: %w", key, p.err)
+		}
+	})
+
+	return p.value, p.err
+}
+
+// lookup finds the provider for the key. Interface types without a provider of their own
+// fall back to the only provider with the same name whose type implements the interface.
+func (c *Container) lookup(key providerKey) (*provider, error) {
+	c.registry.mu.RLock()
+	defer c.registry.mu.RUnlock()
+
+	if p, ok := c.registry.providers[key]; ok {
+		return p, nil
+	}
+
+	if key.typ.Kind() != reflect.Interface {
+		return nil, fmt.Errorf("%w: %s", ErrProviderNotFound, key)
+	}
+
+	var candidates []providerKey
+	for candidate := range c.registry.providers {
+		if candidate.name == key.name && candidate.typ.Implements(key.typ) {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return nil, fmt.Errorf("%w: %s", ErrProviderNotFound, key)
+	case 1:
+		return c.registry.providers[candidates[0]], nil
+	default:
+		names := make([]string, len(candidates))
+		for i, candidate := range candidates {
+			names[i] = candidate.String()
+		}
+		slices.Sort(names)
+		return nil, fmt.Errorf("%w: %s is implemented by %s", ErrAmbiguousProvider, key, strings.Join(names, ", "))
+	}
+}
+
+func formatDependencyPath(path []dependency) string {
+	names := make([]string, len(path))
+	for i, d := range path {
+		names[i] = d.key.String()
+	}
+
+	return strings.Join(names, " -> ")
+}
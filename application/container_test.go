@@ -0,0 +1,206 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/platforma-dev/platforma/application"
+)
+
+type greeter interface {
+	Greet() string
+}
+
+type englishGreeter struct{}
+
+func (englishGreeter) Greet() string { return "hello" }
+
+type frenchGreeter struct{}
+
+func (frenchGreeter) Greet() string { return "bonjour" }
+
+type greetingService struct {
+	greeter greeter
+}
+
+type testDomain struct {
+	service *greetingService
+}
+
+func (d *testDomain) GetRepository() any { return nil }
+
+func TestContainer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("lazy singleton", func(t *testing.T) {
+		t.Parallel()
+
+		var calls atomic.Int32
+		c := application.NewContainer()
+		application.Provide(c, func(_ *application.Container) (*greetingService, error) {
+			calls.Add(1)
+			return &greetingService{}, nil
+		})
+
+		if calls.Load() != 0 {
+			t.Fatalf("expected provider not to be called before resolve")
+		}
+
+		first := application.MustResolve[*greetingService](c)
+		second := application.MustResolve[*greetingService](c)
+
+		if first != second || calls.Load() != 1 {
+			t.Fatalf("expected provider to be called once and its result to be shared, got %d calls", calls.Load())
+		}
+	})
+
+	t.Run("named dependencies", func(t *testing.T) {
+		t.Parallel()
+
+		c := application.NewContainer()
+		application.ProvideNamed(c, "en", func(_ *application.Container) (greeter, error) { return englishGreeter{}, nil })
+		application.ProvideNamed(c, "fr", func(_ *application.Container) (greeter, error) { return frenchGreeter{}, nil })
+
+		fr, err := application.ResolveNamed[greeter](c, "fr")
+		if err != nil || fr.Greet() != "bonjour" {
+			t.Fatalf("expected french greeter, got %v, %v", fr, err)
+		}
+
+		_, err = application.Resolve[greeter](c)
+		if !errors.Is(err, application.ErrProviderNotFound) {
+			t.Fatalf("expected ErrProviderNotFound for unnamed dependency, got %v", err)
+		}
+	})
+
+	t.Run("interface resolved by implementation", func(t *testing.T) {
+		t.Parallel()
+
+		c := application.NewContainer()
+		application.ProvideValue(c, englishGreeter{})
+		application.Provide(c, func(c *application.Container) (*greetingService, error) {
+			g, err := application.Resolve[greeter](c)
+			if err != nil {
+				return nil, err
+			}
+			return &greetingService{greeter: g}, nil
+		})
+
+		service, err := application.Resolve[*greetingService](c)
+		if err != nil || service.greeter.Greet() != "hello" {
+			t.Fatalf("expected service with english greeter, got %v, %v", service, err)
+		}
+	})
+
+	t.Run("ambiguous interface", func(t *testing.T) {
+		t.Parallel()
+
+		c := application.NewContainer()
+		application.ProvideValue(c, englishGreeter{})
+		application.ProvideValue(c, frenchGreeter{})
+
+		_, err := application.Resolve[greeter](c)
+		if !errors.Is(err, application.ErrAmbiguousProvider) {
+			t.Fatalf("expected ErrAmbiguousProvider, got %v", err)
+		}
+	})
+
+	t.Run("dependency cycle", func(t *testing.T) {
+		t.Parallel()
+
+		c := application.NewContainer()
+		application.Provide(c, func(c *application.Container) (*greetingService, error) {
+			_, err := application.Resolve[*testDomain](c)
+			return &greetingService{}, err
+		})
+		application.Provide(c, func(c *application.Container) (*testDomain, error) {
+			service, err := application.Resolve[*greetingService](c)
+			return &testDomain{service: service}, err
+		})
+
+		_, err := application.Resolve[*testDomain](c)
+		if !errors.Is(err, application.ErrDependencyCycle) {
+			t.Fatalf("expected ErrDependencyCycle, got %v", err)
+		}
+	})
+
+	t.Run("inject", func(t *testing.T) {
+		t.Parallel()
+
+		c := application.NewContainer()
+		application.ProvideValue(c, &greetingService{})
+		application.ProvideNamed(c, "fr", func(_ *application.Container) (greeter, error) { return frenchGreeter{}, nil })
+
+		var deps struct {
+			Service  *greetingService `inject:""`
+			Greeter  greeter          `inject:"fr"`
+			Domain   *testDomain      `inject:",optional"`
+			Untagged *greetingService
+		}
+
+		err := application.Inject(c, &deps)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if deps.Service == nil || deps.Greeter.Greet() != "bonjour" || deps.Domain != nil || deps.Untagged != nil {
+			t.Fatalf("unexpected injected dependencies: %+v", deps)
+		}
+
+		var missing struct {
+			Domain *testDomain `inject:""`
+		}
+
+		err = application.Inject(c, &missing)
+		if !errors.Is(err, application.ErrProviderNotFound) {
+			t.Fatalf("expected ErrProviderNotFound, got %v", err)
+		}
+
+		err = application.Inject(c, missing)
+		if !errors.Is(err, application.ErrInvalidInjectTarget) {
+			t.Fatalf("expected ErrInvalidInjectTarget, got %v", err)
+		}
+	})
+}
+
+func TestProvideDomain(t *testing.T) {
+	t.Parallel()
+
+	t.Run("resolved on run", func(t *testing.T) {
+		t.Parallel()
+
+		var resolved atomic.Bool
+		app := application.New()
+		application.ProvideValue(app.Container(), &greetingService{})
+		application.ProvideDomain(app, "greetings", "", func(c *application.Container) (*testDomain, error) {
+			resolved.Store(true)
+			service, err := application.Resolve[*greetingService](c)
+			return &testDomain{service: service}, err
+		})
+
+		err := app.Run(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if !resolved.Load() {
+			t.Fatalf("expected domain to be resolved on run")
+		}
+	})
+
+	t.Run("resolution failure", func(t *testing.T) {
+		t.Parallel()
+
+		app := application.New()
+		application.ProvideDomain(app, "greetings", "", func(c *application.Container) (*testDomain, error) {
+			service, err := application.Resolve[*greetingService](c)
+			return &testDomain{service: service}, err
+		})
+
+		err := app.Run(context.Background())
+		if !errors.Is(err, application.ErrProviderNotFound) {
+			t.Fatalf("expected ErrProviderNotFound, got %v", err)
+		}
+	})
+}
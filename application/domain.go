@@ -1,5 +1,196 @@
 package application
 
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/platforma-dev/platforma/database"
+	"github.com/platforma-dev/platforma/log"
+)
+
+// Domain is a self-contained module of the application. Its repository is registered
+// with the database passed to RegisterDomain.
+//
+// A domain can contribute more to the application by implementing any of
+// ServicesDomain, StartupTasksDomain, HealthChecksDomain, JobsDomain and RoutesDomain.
 type Domain interface {
 	GetRepository() any
 }
+
+// DomainService is a service contributed by a domain.
+type DomainService struct {
+	Name    string
+	Runner  Runner
+	Options []ServiceOption
+}
+
+// ServicesDomain is implemented by domains that contribute long-running services, e.g. queue processors.
+type ServicesDomain interface {
+	Services() []DomainService
+}
+
+// DomainStartupTask is a startup task contributed by a domain.
+type DomainStartupTask struct {
+	Runner Runner
+	Config StartupTaskConfig
+}
+
+// StartupTasksDomain is implemented by domains that contribute startup tasks.
+type StartupTasksDomain interface {
+	StartupTasks() []DomainStartupTask
+}
+
+// HealthChecksDomain is implemented by domains that contribute health checks, keyed by check name.
+type HealthChecksDomain interface {
+	HealthChecks() map[string]Healthchecker
+}
+
+// ScheduledJob is a service that runs periodically and can be run by hand with the run-job command,
+// e.g. scheduler.Scheduler.
+type ScheduledJob interface {
+	Runner
+	Job
+}
+
+// JobsDomain is implemented by domains that contribute scheduled jobs, keyed by job name.
+type JobsDomain interface {
+	Jobs() map[string]ScheduledJob
+}
+
+// RoutesDomain is implemented by domains that serve HTTP routes.
+// Routes are mounted on the router passed to RegisterDomain with MountOn.
+type RoutesDomain interface {
+	Routes() http.Handler
+}
+
+// Router is implemented by HTTP servers and handler groups that domain routes are mounted on,
+// e.g. httpserver.HTTPServer. The handler is expected to see paths with the pattern prefix stripped.
+type Router interface {
+	HandleGroup(pattern string, handler http.Handler)
+}
+
+// DomainOption configures how a domain is registered.
+type DomainOption func(*domainConfig)
+
+type domainConfig struct {
+	router       Router
+	mountPath    string
+	migrateAfter []string
+}
+
+// MountOn mounts routes of a RoutesDomain on the router under the path, e.g. "/auth".
+func MountOn(router Router, path string) DomainOption {
+	return func(c *domainConfig) {
+		c.router = router
+		c.mountPath = path
+	}
+}
+
+// MigrateAfter makes the domain repository migrate after repositories of the given domains,
+// e.g. when its tables reference their tables.
+func MigrateAfter(domainNames ...string) DomainOption {
+	return func(c *domainConfig) {
+		c.migrateAfter = append(c.migrateAfter, domainNames...)
+	}
+}
+
+// RegisterDomain adds a domain to the application in one call. If a database name is provided,
+// the domain repository is registered with the database. Services, startup tasks, health checks
+// and scheduled jobs contributed by the domain are registered under their own names, which must be
+// unique within the application. Routes are mounted on the router given with MountOn.
+func (a *Application) RegisterDomain(name, dbName string, domain Domain, opts ...DomainOption) {
+	config := domainConfig{}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	if dbName != "" {
+		dependencies := make([]string, len(config.migrateAfter))
+		for i, domainName := range config.migrateAfter {
+			dependencies[i] = domainRepositoryName(domainName)
+		}
+
+		repository := domain.GetRepository()
+		a.RegisterRepository(dbName, domainRepositoryName(name), repository, database.DependsOn(dependencies...))
+	}
+
+	if d, ok := domain.(ServicesDomain); ok {
+		for _, service := range d.Services() {
+			a.RegisterService(service.Name, service.Runner, service.Options...)
+		}
+	}
+
+	if d, ok := domain.(StartupTasksDomain); ok {
+		for _, task := range d.StartupTasks() {
+			a.OnStart(task.Runner, task.Config)
+		}
+	}
+
+	if d, ok := domain.(HealthChecksDomain); ok {
+		for checkName, checker := range d.HealthChecks() {
+			a.RegisterHealthCheck(checkName, checker, HealthCheckConfig{})
+		}
+	}
+
+	if d, ok := domain.(JobsDomain); ok {
+		for jobName, job := range d.Jobs() {
+			a.RegisterService(jobName, job)
+		}
+	}
+
+	if d, ok := domain.(RoutesDomain); ok {
+		if config.router != nil {
+			config.router.HandleGroup(config.mountPath, d.Routes())
+		} else {
+			log.Warn("domain routes are not mounted, use MountOn to mount them", "domain", name)
+		}
+	}
+}
+
+// domainRepositoryName returns the name the domain repository is registered under.
+func domainRepositoryName(domainName string) string {
+	return domainName + "_repository"
+}
+
+// domainProvider is a domain registered with ProvideDomain that is resolved when the application starts.
+type domainProvider struct {
+	name    string
+	dbName  string
+	opts    []DomainOption
+	resolve func() (Domain, error)
+}
+
+// ProvideDomain registers the domain provider in the application container.
+// The domain is resolved together with its dependencies after configurations are loaded and
+// before databases are migrated, and then registered the same way as with RegisterDomain.
+// Resolve the domain from the container to use it before the application starts.
+func ProvideDomain[T Domain](a *Application, name, dbName string, provide func(*Container) (T, error), opts ...DomainOption) {
+	Provide(a.container, provide)
+
+	a.domainProviders = append(a.domainProviders, domainProvider{
+		name:   name,
+		dbName: dbName,
+		opts:   opts,
+		resolve: func() (Domain, error) {
+			return Resolve[T](a.container)
+		},
+	})
+}
+
+// resolveDomains resolves domains registered with ProvideDomain in registration order.
+func (a *Application) resolveDomains(ctx context.Context) error {
+	for _, provider := range a.domainProviders {
+		domain, err := provider.resolve()
+		if err != nil {
+			log.ErrorContext(ctx, "error in domain resolution", "error", err, "domain", provider.name)
+			return fmt.Errorf("failed to resolve domain %s: %w", provider.name, err)
+		}
+
+		a.RegisterDomain(provider.name, provider.dbName, domain, provider.opts...)
+	}
+
+	a.domainProviders = nil
+
+	return nil
+}
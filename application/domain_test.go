@@ -0,0 +1,136 @@
+package application_test
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/platforma-dev/platforma/application"
+)
+
+type mockRouter struct {
+	patterns []string
+}
+
+func (r *mockRouter) HandleGroup(pattern string, _ http.Handler) {
+	r.patterns = append(r.patterns, pattern)
+}
+
+type fullDomain struct {
+	serviceRuns atomic.Int32
+	taskRuns    atomic.Int32
+	job         *mockJob
+	checker     mockHealthchecker
+}
+
+func (d *fullDomain) GetRepository() any { return nil }
+
+func (d *fullDomain) Services() []application.DomainService {
+	return []application.DomainService{{
+		Name: "billing-worker",
+		Runner: application.RunnerFunc(func(_ context.Context) error {
+			d.serviceRuns.Add(1)
+			return nil
+		}),
+		Options: []application.ServiceOption{application.Optional()},
+	}}
+}
+
+func (d *fullDomain) StartupTasks() []application.DomainStartupTask {
+	return []application.DomainStartupTask{{
+		Runner: application.RunnerFunc(func(_ context.Context) error {
+			d.taskRuns.Add(1)
+			return nil
+		}),
+		Config: application.StartupTaskConfig{Name: "billing warm up"},
+	}}
+}
+
+func (d *fullDomain) HealthChecks() map[string]application.Healthchecker {
+	return map[string]application.Healthchecker{"billing-provider": &d.checker}
+}
+
+func (d *fullDomain) Jobs() map[string]application.ScheduledJob {
+	return map[string]application.ScheduledJob{"billing-invoices": d.job}
+}
+
+func (d *fullDomain) Routes() http.Handler {
+	return http.NotFoundHandler()
+}
+
+func TestRegisterDomain(t *testing.T) {
+	t.Parallel()
+
+	t.Run("contributions", func(t *testing.T) {
+		t.Parallel()
+
+		domain := &fullDomain{job: &mockJob{}}
+		router := &mockRouter{}
+
+		app := application.New()
+		app.RegisterDomain("billing", "", domain, application.MountOn(router, "/billing"))
+
+		var services []string
+		for _, service := range app.Services() {
+			services = append(services, service.Name)
+		}
+
+		if !slices.Equal(services, []string{"billing-invoices", "billing-worker"}) {
+			t.Fatalf("expected domain services and jobs to be registered, got %v", services)
+		}
+
+		if !slices.Equal(router.patterns, []string{"/billing"}) {
+			t.Fatalf("expected routes to be mounted under /billing, got %v", router.patterns)
+		}
+
+		health := app.Health(context.Background())
+		if len(health.StartupTasks) != 1 || health.StartupTasks[0].Name != "billing warm up" {
+			t.Fatalf("expected domain startup task to be registered, got %+v", health.StartupTasks)
+		}
+
+		err := app.Execute(context.Background(), []string{"run-job", "billing-invoices"})
+		if err != nil {
+			t.Fatalf("expected domain job to run, got %v", err)
+		}
+
+		if domain.job.runs.Load() != 1 {
+			t.Fatalf("expected job to run once, got %d", domain.job.runs.Load())
+		}
+	})
+
+	t.Run("provided domain", func(t *testing.T) {
+		t.Parallel()
+
+		domain := &fullDomain{job: &mockJob{}}
+		router := &mockRouter{}
+
+		app := application.New()
+		application.ProvideDomain(app, "billing", "", func(_ *application.Container) (*fullDomain, error) {
+			return domain, nil
+		}, application.MountOn(router, "/billing"))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			for domain.serviceRuns.Load() == 0 || domain.checker.calls.Load() == 0 {
+				time.Sleep(time.Millisecond)
+			}
+			cancel()
+		}()
+
+		err := app.Run(ctx)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if domain.taskRuns.Load() != 1 || domain.serviceRuns.Load() != 1 {
+			t.Fatalf("expected startup task and service of the domain to run, got %d tasks and %d services", domain.taskRuns.Load(), domain.serviceRuns.Load())
+		}
+
+		if !slices.Equal(router.patterns, []string{"/billing"}) {
+			t.Fatalf("expected routes to be mounted under /billing, got %v", router.patterns)
+		}
+	})
+}
@@ -0,0 +1,151 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/platforma-dev/platforma/log"
+)
+
+// Event is a lifecycle event emitted by the application.
+// Use a type switch to handle particular events.
+type Event interface {
+	// Time returns the moment the event happened.
+	Time() time.Time
+}
+
+// EventTime holds the moment an event happened. It is embedded into all events.
+type EventTime struct {
+	At time.Time
+}
+
+// Time returns the moment the event happened.
+func (e EventTime) Time() time.Time {
+	return e.At
+}
+
+func newEventTime() EventTime {
+	return EventTime{At: time.Now()}
+}
+
+// MigrationStarted is emitted before a registered database is migrated.
+type MigrationStarted struct {
+	EventTime
+
+	Database string
+}
+
+// MigrationFinished is emitted after a registered database is migrated. Err is set if the migration failed.
+type MigrationFinished struct {
+	EventTime
+
+	Database string
+	Duration time.Duration
+	Err      error
+}
+
+// StartupTaskStarted is emitted before every attempt of a startup task.
+type StartupTaskStarted struct {
+	EventTime
+
+	Name    string
+	Index   int // Registration index of the task
+	Attempt int // Attempt number starting from 1
+}
+
+// StartupTaskFinished is emitted when a startup task succeeds.
+type StartupTaskFinished struct {
+	EventTime
+
+	Name     string
+	Index    int
+	Attempts int
+	Duration time.Duration // Total duration of all attempts
+}
+
+// StartupTaskFailed is emitted when an attempt of a startup task fails.
+// Retry reports whether the task is going to be retried.
+type StartupTaskFailed struct {
+	EventTime
+
+	Name    string
+	Index   int
+	Attempt int
+	Err     error
+	Retry   bool
+}
+
+// ServiceStarted is emitted every time a service starts running, including restarts.
+type ServiceStarted struct {
+	EventTime
+
+	Name string
+}
+
+// ServiceStopped is emitted when a service returns without an error or is stopped by the application.
+type ServiceStopped struct {
+	EventTime
+
+	Name string
+}
+
+// ServiceFailed is emitted when a service returns an error.
+type ServiceFailed struct {
+	EventTime
+
+	Name string
+	Err  error
+}
+
+// ServicePanicked is emitted instead of ServiceFailed when a service panics.
+type ServicePanicked struct {
+	EventTime
+
+	Name  string
+	Value any    // Value passed to panic
+	Stack []byte // Stack trace of the panicking goroutine
+}
+
+// ShutdownInitiated is emitted when the application starts shutting down.
+// Err is set if the shutdown was caused by a critical service failure.
+type ShutdownInitiated struct {
+	EventTime
+
+	Err error
+}
+
+// Observer receives lifecycle events of the application.
+type Observer interface {
+	// OnEvent is called synchronously for every event and must not block.
+	// It may be called concurrently, e.g. by services running in parallel.
+	OnEvent(ctx context.Context, event Event)
+}
+
+// ObserverFunc is an adapter to allow the use of ordinary functions as Observers.
+type ObserverFunc func(ctx context.Context, event Event)
+
+// OnEvent calls f(ctx, event).
+func (f ObserverFunc) OnEvent(ctx context.Context, event Event) {
+	f(ctx, event)
+}
+
+// RegisterObserver adds an observer that receives lifecycle events of the application.
+// Observers must be registered before Run is called.
+func (a *Application) RegisterObserver(observer Observer) {
+	a.observers = append(a.observers, observer)
+}
+
+// emit passes the event to all observers. Panics in observers are recovered and logged.
+func (a *Application) emit(ctx context.Context, event Event) {
+	for _, observer := range a.observers {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					log.ErrorContext(ctx, "observer panicked", "panic", r)
+				}
+			}()
+
+			observer.OnEvent(ctx, event)
+		}()
+	}
+}
@@ -0,0 +1,174 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/platforma-dev/platforma/application"
+)
+
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []application.Event
+}
+
+func (r *eventRecorder) OnEvent(_ context.Context, event application.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, event)
+}
+
+// names returns short descriptions of recorded events of the given types.
+func (r *eventRecorder) names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := []string{}
+	for _, event := range r.events {
+		switch e := event.(type) {
+		case application.StartupTaskStarted:
+			names = append(names, fmt.Sprintf("task started %s #%d", e.Name, e.Attempt))
+		case application.StartupTaskFailed:
+			names = append(names, fmt.Sprintf("task failed %s #%d retry=%t", e.Name, e.Attempt, e.Retry))
+		case application.StartupTaskFinished:
+			names = append(names, fmt.Sprintf("task finished %s attempts=%d", e.Name, e.Attempts))
+		case application.ServiceStarted:
+			names = append(names, "service started "+e.Name)
+		case application.ServiceStopped:
+			names = append(names, "service stopped "+e.Name)
+		case application.ServiceFailed:
+			names = append(names, "service failed "+e.Name)
+		case application.ServicePanicked:
+			names = append(names, fmt.Sprintf("service panicked %s: %v", e.Name, e.Value))
+		case application.ShutdownInitiated:
+			names = append(names, "shutdown")
+		}
+	}
+
+	return names
+}
+
+func TestObserver(t *testing.T) {
+	t.Parallel()
+
+	t.Run("startup tasks", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := &eventRecorder{}
+		attempts := 0
+
+		app := application.New()
+		app.RegisterObserver(recorder)
+		app.OnStartFunc(func(_ context.Context) error {
+			attempts++
+			if attempts == 1 {
+				return errors.New("boom")
+			}
+			return nil
+		}, application.StartupTaskConfig{Name: "task", Retries: 1, RetryBackoff: time.Millisecond})
+
+		err := app.Run(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		expected := []string{
+			"task started task #1",
+			"task failed task #1 retry=true",
+			"task started task #2",
+			"task finished task attempts=2",
+			"shutdown",
+		}
+		if got := recorder.names(); !slices.Equal(got, expected) {
+			t.Fatalf("expected events %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("services", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := &eventRecorder{}
+
+		app := application.New()
+		app.RegisterObserver(recorder)
+		app.RegisterService("panicking", application.RunnerFunc(func(_ context.Context) error {
+			panic("oops")
+		}))
+		app.RegisterService("failing", application.RunnerFunc(func(_ context.Context) error {
+			return errors.New("boom")
+		}))
+		app.RegisterService("stopping", application.RunnerFunc(func(_ context.Context) error {
+			return nil
+		}))
+
+		err := app.Run(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		got := recorder.names()
+		for _, name := range []string{
+			"service started panicking",
+			"service panicked panicking: oops",
+			"service failed failing",
+			"service stopped stopping",
+		} {
+			if !slices.Contains(got, name) {
+				t.Fatalf("expected event %q, got %v", name, got)
+			}
+		}
+
+		if slices.Contains(got, "service failed panicking") {
+			t.Fatalf("expected panicking service not to be reported as failed, got %v", got)
+		}
+
+		if got[len(got)-1] != "shutdown" {
+			t.Fatalf("expected shutdown to be the last event, got %v", got)
+		}
+	})
+
+	t.Run("shutdown caused by critical service", func(t *testing.T) {
+		t.Parallel()
+
+		var shutdownErr error
+		app := application.New()
+		app.RegisterObserver(application.ObserverFunc(func(_ context.Context, event application.Event) {
+			if e, ok := event.(application.ShutdownInitiated); ok {
+				shutdownErr = e.Err
+			}
+		}))
+		app.RegisterService("svc", application.RunnerFunc(func(_ context.Context) error {
+			return errors.New("boom")
+		}), application.Critical())
+
+		_ = app.Run(context.Background())
+
+		var serviceErr *application.ErrServiceFailed
+		if !errors.As(shutdownErr, &serviceErr) {
+			t.Fatalf("expected shutdown to be caused by ErrServiceFailed, got %v", shutdownErr)
+		}
+	})
+
+	t.Run("observer panic is recovered", func(t *testing.T) {
+		t.Parallel()
+
+		app := application.New()
+		app.RegisterObserver(application.ObserverFunc(func(_ context.Context, _ application.Event) {
+			panic("observer")
+		}))
+		app.OnStartFunc(func(_ context.Context) error {
+			return nil
+		}, application.StartupTaskConfig{Name: "task"})
+
+		err := app.Run(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+}
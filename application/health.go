@@ -2,70 +2,319 @@ package application
 
 import (
 	"encoding/json"
+	"maps"
+	"slices"
+	"sync"
 	"time"
 )
 
+// maxStatusHistory is the maximum number of status transitions kept for every service.
+const maxStatusHistory = 20
+
 type ServiceStatus string
 
 const (
 	ServiceStatusNotStarted ServiceStatus = "NOT_STARTED"
-	ServiceStatusStarted    ServiceStatus = "STARTED"
-	ServiceStatusError      ServiceStatus = "ERROR"
+	ServiceStatusStarting   ServiceStatus = "STARTING"
+	ServiceStatusRunning    ServiceStatus = "RUNNING"
+	ServiceStatusStopping   ServiceStatus = "STOPPING"
+	ServiceStatusStopped    ServiceStatus = "STOPPED"
+	ServiceStatusFailed     ServiceStatus = "FAILED"
+	ServiceStatusRestarting ServiceStatus = "RESTARTING"
+)
+
+// StartupTaskStatus is the outcome of a startup task.
+type StartupTaskStatus string
+
+const (
+	StartupTaskStatusPending   StartupTaskStatus = "PENDING"
+	StartupTaskStatusRunning   StartupTaskStatus = "RUNNING"
+	StartupTaskStatusSucceeded StartupTaskStatus = "SUCCEEDED"
+	StartupTaskStatusFailed    StartupTaskStatus = "FAILED"
 )
 
+// StartupTaskHealth holds the outcome of a startup task.
+type StartupTaskHealth struct {
+	Name       string            `json:"name"`
+	Status     StartupTaskStatus `json:"status"`
+	StartedAt  *time.Time        `json:"startedAt,omitempty"`
+	FinishedAt *time.Time        `json:"finishedAt,omitempty"`
+	Duration   time.Duration     `json:"duration"` // Total duration of all attempts including retry delays
+	Attempts   int               `json:"attempts"`
+	Error      string            `json:"error,omitempty"`
+	LastError  string            `json:"lastError,omitempty"` // Error of the latest failed attempt
+}
+
+// StatusTransition records the moment a service entered a status.
+type StatusTransition struct {
+	Status ServiceStatus `json:"status"`
+	At     time.Time     `json:"at"`
+}
+
+// ServiceStatusChange is passed to health subscribers every time a service changes its status.
+type ServiceStatusChange struct {
+	ServiceName string
+	From        ServiceStatus
+	To          ServiceStatus
+	At          time.Time
+	Error       string // Error that caused the service to fail, if any
+}
+
 type ServiceHealth struct {
-	Status    ServiceStatus `json:"status"`
-	StartedAt *time.Time    `json:"startedAt"`
-	StoppedAt *time.Time    `json:"stoppedAt,omitempty"`
-	Error     string        `json:"error,omitempty"`
-	Data      any           `json:"data,omitempty"`
+	Status    ServiceStatus      `json:"status"`
+	StartedAt *time.Time         `json:"startedAt"`
+	StoppedAt *time.Time         `json:"stoppedAt,omitempty"`
+	Restarts  int                `json:"restarts"`
+	Error     string             `json:"error,omitempty"`
+	LastError string             `json:"lastError,omitempty"`
+	History   []StatusTransition `json:"history,omitempty"`
 }
 
+// ApplicationHealth is a concurrency-safe registry of service statuses.
+// Exported fields must only be read from snapshots returned by Snapshot.
 type ApplicationHealth struct {
-	StartedAt time.Time                 `json:"startedAt"`
-	Services  map[string]*ServiceHealth `json:"services"`
+	Status    HealthStatus                  `json:"status,omitempty"` // Overall verdict, set by Application.Health
+	StartedAt time.Time                     `json:"startedAt"`
+	Services  map[string]*ServiceHealth     `json:"services"`
+	Checks    map[string]*HealthCheckResult `json:"checks,omitempty"` // Latest results of health checks
+
+	StartupTasks []*StartupTaskHealth `json:"startupTasks,omitempty"` // Startup tasks in registration order
+
+	mu          sync.RWMutex
+	subscribers map[int]func(ServiceStatusChange)
+	nextSubID   int
 }
 
 func NewApplicationHealth() *ApplicationHealth {
-	return &ApplicationHealth{Services: make(map[string]*ServiceHealth)}
+	return &ApplicationHealth{Services: make(map[string]*ServiceHealth), Checks: make(map[string]*HealthCheckResult), subscribers: make(map[int]func(ServiceStatusChange))}
 }
 
-func (h *ApplicationHealth) StartService(serviceName string) {
-	if service, ok := h.Services[serviceName]; ok {
-		service.Status = ServiceStatusStarted
+// Subscribe registers a function that is called every time a service changes its status.
+// The function is called synchronously and must not block. Call the returned function to unsubscribe.
+func (h *ApplicationHealth) Subscribe(fn func(ServiceStatusChange)) func() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 
-		st := time.Now()
-		service.StartedAt = &st
+	id := h.nextSubID
+	h.nextSubID++
+	h.subscribers[id] = fn
 
-		h.Services[serviceName] = service
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subscribers, id)
 	}
 }
 
-func (h *ApplicationHealth) FailService(serviceName string, err error) {
-	if service, ok := h.Services[serviceName]; ok {
-		service.Status = ServiceStatusError
+// Snapshot returns a copy of the current health that is safe to read and serialize.
+func (h *ApplicationHealth) Snapshot() *ApplicationHealth {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	snapshot := &ApplicationHealth{
+		Status:    h.Status,
+		StartedAt: h.StartedAt,
+		Services:  make(map[string]*ServiceHealth, len(h.Services)),
+		Checks:    make(map[string]*HealthCheckResult, len(h.Checks)),
+	}
+	for name, service := range h.Services {
+		serviceCopy := *service
+		serviceCopy.History = slices.Clone(service.History)
+		snapshot.Services[name] = &serviceCopy
+	}
+	for name, result := range h.Checks {
+		resultCopy := *result
+		snapshot.Checks[name] = &resultCopy
+	}
+	for _, task := range h.StartupTasks {
+		taskCopy := *task
+		snapshot.StartupTasks = append(snapshot.StartupTasks, &taskCopy)
+	}
+
+	return snapshot
+}
+
+// Service returns a copy of the health of the named service.
+func (h *ApplicationHealth) Service(serviceName string) (ServiceHealth, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	service, ok := h.Services[serviceName]
+	if !ok {
+		return ServiceHealth{}, false
+	}
+
+	serviceCopy := *service
+	serviceCopy.History = slices.Clone(service.History)
+
+	return serviceCopy, true
+}
+
+// AddService adds a service to the registry in NOT_STARTED status.
+func (h *ApplicationHealth) AddService(serviceName string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.Services[serviceName] = &ServiceHealth{
+		Status:  ServiceStatusNotStarted,
+		History: []StatusTransition{{Status: ServiceStatusNotStarted, At: time.Now()}},
+	}
+}
+
+// StartService moves the service to STARTING status.
+func (h *ApplicationHealth) StartService(serviceName string) {
+	h.transition(serviceName, ServiceStatusStarting, func(service *ServiceHealth, now time.Time) {
+		service.Error = ""
+		service.StartedAt = &now
+		service.StoppedAt = nil
+	})
+}
+
+// ReadyService moves the service from STARTING to RUNNING status.
+func (h *ApplicationHealth) ReadyService(serviceName string) {
+	h.transition(serviceName, ServiceStatusRunning, nil, ServiceStatusStarting)
+}
+
+// StopService moves the service to STOPPING status if it is starting, running or restarting.
+func (h *ApplicationHealth) StopService(serviceName string) {
+	h.transition(serviceName, ServiceStatusStopping, nil, ServiceStatusStarting, ServiceStatusRunning, ServiceStatusRestarting)
+}
 
-		st := time.Now()
-		service.StoppedAt = &st
+// FinishService moves the service to STOPPED status.
+func (h *ApplicationHealth) FinishService(serviceName string) {
+	h.transition(serviceName, ServiceStatusStopped, func(service *ServiceHealth, now time.Time) {
+		service.StoppedAt = &now
+	})
+}
 
+// FailService moves the service to FAILED status and records the error.
+func (h *ApplicationHealth) FailService(serviceName string, err error) {
+	h.transition(serviceName, ServiceStatusFailed, func(service *ServiceHealth, now time.Time) {
+		service.StoppedAt = &now
 		service.Error = err.Error()
+		service.LastError = err.Error()
+	})
+}
+
+// RestartService moves the service to RESTARTING status and increments its restart counter.
+func (h *ApplicationHealth) RestartService(serviceName string) {
+	h.transition(serviceName, ServiceStatusRestarting, func(service *ServiceHealth, _ time.Time) {
+		service.Restarts++
+	})
+}
+
+// AddStartupTask adds a startup task to the registry in PENDING status.
+// Startup tasks are identified by their registration index.
+func (h *ApplicationHealth) AddStartupTask(taskName string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.StartupTasks = append(h.StartupTasks, &StartupTaskHealth{Name: taskName, Status: StartupTaskStatusPending})
+}
+
+// StartStartupTask moves the startup task with the given index to RUNNING status and counts a new attempt.
+func (h *ApplicationHealth) StartStartupTask(index int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 
-		h.Services[serviceName] = service
+	if index < 0 || index >= len(h.StartupTasks) {
+		return
+	}
+
+	now := time.Now()
+	task := h.StartupTasks[index]
+	task.Status = StartupTaskStatusRunning
+	task.Attempts++
+	if task.StartedAt == nil {
+		task.StartedAt = &now
 	}
 }
 
-func (h *ApplicationHealth) SetServiceData(serviceName string, data any) {
-	if service, ok := h.Services[serviceName]; ok {
-		service.Data = data
-		h.Services[serviceName] = service
+// FailStartupTaskAttempt records the error of a failed attempt that is going to be retried.
+func (h *ApplicationHealth) FailStartupTaskAttempt(index int, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if index < 0 || index >= len(h.StartupTasks) {
+		return
+	}
+
+	h.StartupTasks[index].LastError = err.Error()
+}
+
+// FinishStartupTask moves the startup task with the given index to SUCCEEDED or FAILED status depending on err.
+func (h *ApplicationHealth) FinishStartupTask(index int, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if index < 0 || index >= len(h.StartupTasks) {
+		return
+	}
+
+	now := time.Now()
+	task := h.StartupTasks[index]
+	task.FinishedAt = &now
+	if task.StartedAt != nil {
+		task.Duration = now.Sub(*task.StartedAt)
+	}
+	task.Status = StartupTaskStatusSucceeded
+	if err != nil {
+		task.Status = StartupTaskStatusFailed
+		task.Error = err.Error()
+		task.LastError = err.Error()
 	}
 }
 
+// SetCheckResult stores the latest result of the named health check.
+func (h *ApplicationHealth) SetCheckResult(checkName string, result HealthCheckResult) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.Checks[checkName] = &result
+}
+
 func (h *ApplicationHealth) String() string {
-	b, _ := json.Marshal(h)
+	b, _ := json.Marshal(h.Snapshot())
 	return string(b)
 }
 
 func (h *ApplicationHealth) StartApplication() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
 	h.StartedAt = time.Now()
 }
+
+// transition moves the service to the given status and notifies subscribers.
+// If allowedFrom is not empty, the transition happens only from one of the listed statuses.
+func (h *ApplicationHealth) transition(serviceName string, to ServiceStatus, update func(*ServiceHealth, time.Time), allowedFrom ...ServiceStatus) {
+	h.mu.Lock()
+
+	service, ok := h.Services[serviceName]
+	if !ok || (len(allowedFrom) > 0 && !slices.Contains(allowedFrom, service.Status)) {
+		h.mu.Unlock()
+		return
+	}
+
+	now := time.Now()
+	change := ServiceStatusChange{ServiceName: serviceName, From: service.Status, To: to, At: now}
+
+	service.Status = to
+	if update != nil {
+		update(service, now)
+	}
+	change.Error = service.Error
+
+	service.History = append(service.History, StatusTransition{Status: to, At: now})
+	if len(service.History) > maxStatusHistory {
+		service.History = slices.Clone(service.History[len(service.History)-maxStatusHistory:])
+	}
+
+	subscribers := slices.Collect(maps.Values(h.subscribers))
+
+	h.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(change)
+	}
+}
@@ -0,0 +1,163 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/platforma-dev/platforma/application"
+)
+
+func TestApplicationHealth(t *testing.T) {
+	t.Parallel()
+
+	t.Run("status transitions", func(t *testing.T) {
+		t.Parallel()
+
+		app := application.New()
+		app.RegisterService("api", &mockService{})
+
+		var (
+			mu          sync.Mutex
+			transitions []application.ServiceStatus
+		)
+		app.SubscribeHealth(func(change application.ServiceStatusChange) {
+			mu.Lock()
+			defer mu.Unlock()
+			transitions = append(transitions, change.To)
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			cancel()
+		}()
+
+		err := app.Run(ctx)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		expected := []application.ServiceStatus{
+			application.ServiceStatusStarting,
+			application.ServiceStatusRunning,
+			application.ServiceStatusStopping,
+			application.ServiceStatusStopped,
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if !slices.Equal(transitions, expected) {
+			t.Fatalf("expected transitions %v, got %v", expected, transitions)
+		}
+
+		health := app.Health(context.Background())
+		if len(health.Services["api"].History) != len(expected)+1 {
+			t.Fatalf("expected %d history entries, got %d", len(expected)+1, len(health.Services["api"].History))
+		}
+
+		if health.Services["api"].StoppedAt == nil {
+			t.Fatal("expected stoppedAt to be set")
+		}
+	})
+
+	t.Run("failed and restarting statuses", func(t *testing.T) {
+		t.Parallel()
+
+		health := application.NewApplicationHealth()
+		health.AddService("svc")
+
+		var changes []application.ServiceStatusChange
+		unsubscribe := health.Subscribe(func(change application.ServiceStatusChange) {
+			changes = append(changes, change)
+		})
+
+		health.StartService("svc")
+		health.FailService("svc", errors.New("boom"))
+		health.RestartService("svc")
+
+		unsubscribe()
+		health.StartService("svc")
+
+		if len(changes) != 3 {
+			t.Fatalf("expected 3 changes, got %d", len(changes))
+		}
+
+		if changes[1].To != application.ServiceStatusFailed || changes[1].Error != "boom" {
+			t.Fatalf("expected failed change with error, got %+v", changes[1])
+		}
+
+		if changes[2].From != application.ServiceStatusFailed || changes[2].To != application.ServiceStatusRestarting {
+			t.Fatalf("expected change from failed to restarting, got %+v", changes[2])
+		}
+
+		svc, ok := health.Service("svc")
+		if !ok {
+			t.Fatal("expected service to exist")
+		}
+
+		if svc.Restarts != 1 || svc.LastError != "boom" || svc.Error != "" {
+			t.Fatalf("unexpected service health: %+v", svc)
+		}
+	})
+
+	t.Run("startup task outcomes", func(t *testing.T) {
+		t.Parallel()
+
+		app := application.New()
+		app.OnStartFunc(func(_ context.Context) error {
+			return nil
+		}, application.StartupTaskConfig{Name: "ok"})
+		app.OnStartFunc(func(_ context.Context) error {
+			return errors.New("boom")
+		}, application.StartupTaskConfig{Name: "fail", AbortOnError: true})
+		app.OnStartFunc(func(_ context.Context) error {
+			return nil
+		}, application.StartupTaskConfig{Name: "skipped"})
+
+		_ = app.Run(context.Background())
+
+		tasks := app.Health(context.Background()).StartupTasks
+		if len(tasks) != 3 {
+			t.Fatalf("expected 3 startup tasks, got %d", len(tasks))
+		}
+
+		expected := []application.StartupTaskStatus{
+			application.StartupTaskStatusSucceeded,
+			application.StartupTaskStatusFailed,
+			application.StartupTaskStatusPending,
+		}
+		for i, task := range tasks {
+			if task.Status != expected[i] {
+				t.Fatalf("expected task %s to be %s, got %s", task.Name, expected[i], task.Status)
+			}
+		}
+
+		if tasks[1].Error != "boom" || tasks[1].FinishedAt == nil {
+			t.Fatalf("unexpected failed task health: %+v", tasks[1])
+		}
+	})
+
+	t.Run("concurrent access", func(t *testing.T) {
+		t.Parallel()
+
+		health := application.NewApplicationHealth()
+		health.AddService("svc")
+
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Go(func() {
+				for range 100 {
+					health.StartService("svc")
+					health.SetCheckResult("svc", application.HealthCheckResult{Status: application.HealthStatusHealthy})
+					_ = health.String()
+					health.FailService("svc", errors.New("boom"))
+				}
+			})
+		}
+		wg.Wait()
+	})
+}
@@ -12,10 +12,13 @@ type healther interface {
 	Health(context.Context) *ApplicationHealth
 }
 
+// HealthCheckHandler is an HTTP handler that exposes application health as JSON.
+// It serves cached health check results and responds with 503 when the application is unhealthy.
 type HealthCheckHandler struct {
 	app healther
 }
 
+// NewHealthCheckHandler creates a new HealthCheckHandler for the given application.
 func NewHealthCheckHandler(app healther) *HealthCheckHandler {
 	return &HealthCheckHandler{app: app}
 }
@@ -24,7 +27,12 @@ func (h *HealthCheckHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	health := h.app.Health(r.Context())
 
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
+
+	if health.Status == HealthStatusUnhealthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
 
 	err := json.NewEncoder(w).Encode(health)
 	if err != nil {
@@ -0,0 +1,167 @@
+package application_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/platforma-dev/platforma/application"
+)
+
+type mockHealthchecker struct {
+	calls  atomic.Int32
+	delay  time.Duration
+	result application.HealthCheckResult
+}
+
+func (c *mockHealthchecker) Healthcheck(ctx context.Context) application.HealthCheckResult {
+	c.calls.Add(1)
+
+	select {
+	case <-time.After(c.delay):
+	case <-ctx.Done():
+	}
+
+	return c.result
+}
+
+// runApplication runs the application with a long running service until the test ends.
+func runApplication(t *testing.T, app *application.Application) {
+	t.Helper()
+
+	app.RegisterService("blocker", application.RunnerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go app.Run(ctx)
+}
+
+func TestHealthChecks(t *testing.T) {
+	t.Parallel()
+
+	t.Run("results are cached between intervals", func(t *testing.T) {
+		t.Parallel()
+
+		checker := &mockHealthchecker{result: application.HealthCheckResult{Details: "ok"}}
+
+		app := application.New(application.WithHealthCheckInterval(time.Hour))
+		app.RegisterHealthCheck("db", checker, application.HealthCheckConfig{})
+		runApplication(t, app)
+
+		time.Sleep(50 * time.Millisecond)
+
+		for range 3 {
+			health := app.Health(context.Background())
+
+			result, ok := health.Checks["db"]
+			if !ok {
+				t.Fatal("expected check result to be cached")
+			}
+
+			if result.Status != application.HealthStatusHealthy || result.CheckedAt.IsZero() {
+				t.Fatalf("unexpected check result: %+v", result)
+			}
+		}
+
+		if checker.calls.Load() != 1 {
+			t.Fatalf("expected 1 check run, got %d", checker.calls.Load())
+		}
+	})
+
+	t.Run("checks run on interval", func(t *testing.T) {
+		t.Parallel()
+
+		checker := &mockHealthchecker{}
+
+		app := application.New()
+		app.RegisterHealthCheck("db", checker, application.HealthCheckConfig{Interval: 10 * time.Millisecond})
+		runApplication(t, app)
+
+		time.Sleep(100 * time.Millisecond)
+
+		if checker.calls.Load() < 3 {
+			t.Fatalf("expected at least 3 check runs, got %d", checker.calls.Load())
+		}
+	})
+
+	t.Run("slow check times out", func(t *testing.T) {
+		t.Parallel()
+
+		checker := &mockHealthchecker{delay: time.Hour}
+
+		app := application.New()
+		app.RegisterHealthCheck("db", checker, application.HealthCheckConfig{Timeout: 10 * time.Millisecond})
+		runApplication(t, app)
+
+		time.Sleep(50 * time.Millisecond)
+
+		health := app.Health(context.Background())
+		if health.Checks["db"].Status != application.HealthStatusUnhealthy {
+			t.Fatalf("expected unhealthy check, got %+v", health.Checks["db"])
+		}
+
+		if health.Status != application.HealthStatusUnhealthy {
+			t.Fatalf("expected unhealthy application, got %s", health.Status)
+		}
+	})
+
+	t.Run("degraded check degrades application", func(t *testing.T) {
+		t.Parallel()
+
+		app := application.New()
+		app.RegisterHealthCheck("ok", &mockHealthchecker{}, application.HealthCheckConfig{})
+		app.RegisterHealthCheck("slow", &mockHealthchecker{result: application.HealthCheckResult{
+			Status:  application.HealthStatusDegraded,
+			Message: "high latency",
+		}}, application.HealthCheckConfig{})
+		runApplication(t, app)
+
+		time.Sleep(50 * time.Millisecond)
+
+		health := app.Health(context.Background())
+		if health.Status != application.HealthStatusDegraded {
+			t.Fatalf("expected degraded application, got %s", health.Status)
+		}
+	})
+
+	t.Run("handler responds with 503 when unhealthy", func(t *testing.T) {
+		t.Parallel()
+
+		app := application.New()
+		app.RegisterHealthCheck("db", &mockHealthchecker{result: application.HealthCheckResult{
+			Status:  application.HealthStatusUnhealthy,
+			Message: "connection refused",
+		}}, application.HealthCheckConfig{})
+		runApplication(t, app)
+
+		time.Sleep(50 * time.Millisecond)
+
+		w := httptest.NewRecorder()
+		application.NewHealthCheckHandler(app).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Fatalf("expected status 503, got %d", w.Code)
+		}
+
+		var body struct {
+			Status application.HealthStatus                 `json:"status"`
+			Checks map[string]application.HealthCheckResult `json:"checks"`
+		}
+		err := json.NewDecoder(w.Body).Decode(&body)
+		if err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+
+		if body.Status != application.HealthStatusUnhealthy || body.Checks["db"].Message != "connection refused" {
+			t.Fatalf("unexpected response body: %+v", body)
+		}
+	})
+}
@@ -1,9 +1,196 @@
 package application
 
-import "context"
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/platforma-dev/platforma/database"
+)
+
+const (
+	defaultHealthCheckInterval = 30 * time.Second
+	defaultHealthCheckTimeout  = 5 * time.Second
+)
+
+// HealthStatus is the verdict of a health check.
+type HealthStatus string
+
+const (
+	HealthStatusHealthy   HealthStatus = "HEALTHY"
+	HealthStatusDegraded  HealthStatus = "DEGRADED"
+	HealthStatusUnhealthy HealthStatus = "UNHEALTHY"
+)
+
+// severity orders health statuses from the best to the worst.
+func (s HealthStatus) severity() int {
+	switch s {
+	case HealthStatusHealthy:
+		return 0
+	case HealthStatusDegraded:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// worse returns the worst of two health statuses.
+func (s HealthStatus) worse(other HealthStatus) HealthStatus {
+	if other.severity() > s.severity() {
+		return other
+	}
+	return s
+}
+
+// HealthCheckResult is the outcome of a single health check.
+type HealthCheckResult struct {
+	Status    HealthStatus  `json:"status"`
+	Message   string        `json:"message,omitempty"` // Human readable reason of a degraded or unhealthy status
+	Details   any           `json:"details,omitempty"` // Check specific data, e.g. pool stats
+	CheckedAt time.Time     `json:"checkedAt"`         // Set by the application when the check starts
+	Duration  time.Duration `json:"duration"`          // Set by the application when the check finishes
+}
 
 // Healthchecker represents a service that can perform health checks.
 type Healthchecker interface {
 	// Healthcheck returns the health status of the service.
-	Healthcheck(context.Context) any
+	// An empty Status is treated as HealthStatusHealthy.
+	Healthcheck(context.Context) HealthCheckResult
+}
+
+// ReadinessChecker represents a service that can report whether it is ready to accept work.
+type ReadinessChecker interface {
+	// Readiness returns nil if the service is ready or an error describing why it is not.
+	Readiness(context.Context) error
+}
+
+// HealthCheckConfig holds configuration for a health check.
+// Zero values fall back to the application defaults.
+type HealthCheckConfig struct {
+	Interval time.Duration // Time between two consecutive checks
+	Timeout  time.Duration // Maximum duration of a single check
+}
+
+type healthCheck struct {
+	checker Healthchecker
+	config  HealthCheckConfig
+}
+
+// databaseHealthchecker reports database ping latency, pool stats and applied migrations.
+type databaseHealthchecker struct {
+	db *database.Database
+}
+
+func (c *databaseHealthchecker) Healthcheck(ctx context.Context) HealthCheckResult {
+	health, err := c.db.Health(ctx)
+	if err != nil {
+		return HealthCheckResult{Status: HealthStatusUnhealthy, Message: err.Error(), Details: health}
+	}
+
+	return HealthCheckResult{Status: HealthStatusHealthy, Details: health}
+}
+
+// startHealthChecks runs all registered health checks in background until ctx is cancelled.
+// The returned channel is closed when all checks have stopped.
+func (a *Application) startHealthChecks(ctx context.Context) <-chan struct{} {
+	var wg sync.WaitGroup
+	for checkName, check := range a.healthchecks {
+		wg.Go(func() {
+			a.runHealthCheckLoop(ctx, checkName, check)
+		})
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	return done
+}
+
+// runHealthCheckLoop runs the check immediately and then on every interval, caching its results.
+func (a *Application) runHealthCheckLoop(ctx context.Context, checkName string, check *healthCheck) {
+	interval := cmp.Or(check.config.Interval, a.healthCheckInterval, defaultHealthCheckInterval)
+	timeout := cmp.Or(check.config.Timeout, a.healthCheckTimeout, defaultHealthCheckTimeout)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result := runHealthCheck(ctx, check.checker, timeout)
+		if ctx.Err() != nil {
+			return
+		}
+		a.health.SetCheckResult(checkName, result)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runHealthCheck runs a single check. Checks that don't finish within timeout or panic are unhealthy.
+func runHealthCheck(ctx context.Context, checker Healthchecker, timeout time.Duration) HealthCheckResult {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	startedAt := time.Now()
+
+	results := make(chan HealthCheckResult, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				results <- HealthCheckResult{Status: HealthStatusUnhealthy, Message: fmt.Sprintf("health check panicked: %v", r)}
+			}
+		}()
+
+		results <- checker.Healthcheck(ctx)
+	}()
+
+	var result HealthCheckResult
+	select {
+	case result = <-results:
+	case <-ctx.Done():
+		result = HealthCheckResult{Status: HealthStatusUnhealthy, Message: fmt.Sprintf("health check timed out after %s", timeout)}
+	}
+
+	if result.Status == "" {
+		result.Status = HealthStatusHealthy
+	}
+	result.CheckedAt = startedAt
+	result.Duration = time.Since(startedAt)
+
+	return result
+}
+
+// healthStatus returns the overall verdict for the health snapshot.
+// The application is unhealthy if any check is unhealthy or a required service has failed,
+// and degraded if any check is degraded or an optional service has failed or is restarting.
+func (a *Application) healthStatus(health *ApplicationHealth) HealthStatus {
+	status := HealthStatusHealthy
+
+	for serviceName, serviceHealth := range health.Services {
+		switch serviceHealth.Status {
+		case ServiceStatusFailed:
+			if service, ok := a.services[serviceName]; ok && service.config.optional {
+				status = status.worse(HealthStatusDegraded)
+			} else {
+				status = status.worse(HealthStatusUnhealthy)
+			}
+		case ServiceStatusRestarting:
+			status = status.worse(HealthStatusDegraded)
+		default:
+		}
+	}
+
+	for _, result := range health.Checks {
+		status = status.worse(result.Status)
+	}
+
+	return status
 }
@@ -0,0 +1,189 @@
+package application
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/platforma-dev/platforma/database"
+)
+
+const migrateUsage = "[-database name] [-dry-run] [-allow-drift] [status|up|down [count]|to <migration>|redo]"
+
+// migrateOperation is a parsed migrate command.
+type migrateOperation struct {
+	name       string // One of status, up, down, to and redo
+	count      int    // Number of migrations rolled back by down
+	target     string // Target migration of to
+	dryRun     bool
+	allowDrift bool
+}
+
+// migrateCommand shows, applies or rolls back migrations of registered databases.
+func (a *Application) migrateCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	dbName := flags.String("database", "", "")
+	dryRun := flags.Bool("dry-run", false, "")
+	allowDrift := flags.Bool("allow-drift", false, "")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUsage, err)
+	}
+
+	operation, err := parseMigrateOperation(flags.Args())
+	if err != nil {
+		return err
+	}
+	operation.dryRun = *dryRun
+	operation.allowDrift = *allowDrift
+
+	dbNames := slices.Sorted(maps.Keys(a.databases))
+	if *dbName != "" {
+		if _, ok := a.databases[*dbName]; !ok {
+			return fmt.Errorf("%w: %w: %s", ErrUsage, ErrUnknownDatabase, *dbName)
+		}
+		dbNames = []string{*dbName}
+	}
+
+	// Rolling back makes sense only for a particular database
+	if operation.name != "status" && operation.name != "up" && len(dbNames) > 1 {
+		return fmt.Errorf("%w: %s requires -database when several databases are registered", ErrUsage, operation.name)
+	}
+
+	for _, name := range dbNames {
+		err := a.runMigrateOperation(ctx, name, operation)
+		if err != nil {
+			return fmt.Errorf("database %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func parseMigrateOperation(args []string) (migrateOperation, error) {
+	if len(args) == 0 {
+		return migrateOperation{name: "up"}, nil
+	}
+
+	operation := migrateOperation{name: args[0]}
+	args = args[1:]
+
+	switch operation.name {
+	case "status", "up", "redo":
+		if len(args) > 0 {
+			return operation, fmt.Errorf("%w: unexpected arguments %v", ErrUsage, args)
+		}
+	case "down":
+		operation.count = 1
+		if len(args) > 1 {
+			return operation, fmt.Errorf("%w: unexpected arguments %v", ErrUsage, args[1:])
+		}
+		if len(args) == 1 {
+			count, err := strconv.Atoi(args[0])
+			if err != nil || count < 1 {
+				return operation, fmt.Errorf("%w: invalid count %q", ErrUsage, args[0])
+			}
+			operation.count = count
+		}
+	case "to":
+		if len(args) != 1 {
+			return operation, fmt.Errorf("%w: expected target migration", ErrUsage)
+		}
+		operation.target = args[0]
+	default:
+		return operation, fmt.Errorf("%w: unknown operation %q", ErrUsage, operation.name)
+	}
+
+	return operation, nil
+}
+
+func (a *Application) runMigrateOperation(ctx context.Context, dbName string, operation migrateOperation) error {
+	db := a.databases[dbName]
+
+	opts := slices.Clone(a.migrateOptions[dbName])
+	if operation.dryRun {
+		opts = append(opts, database.DryRun())
+	}
+	if operation.allowDrift {
+		opts = append(opts, database.AllowDrift())
+	}
+
+	if operation.name == "status" {
+		statuses, err := db.MigrationStatus(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get migration status: %w", err)
+		}
+
+		a.printMigrationStatus(dbName, statuses)
+		return nil
+	}
+
+	var steps []database.MigrationStep
+	var err error
+
+	switch operation.name {
+	case "up":
+		steps, err = db.MigrateUp(ctx, opts...)
+	case "down":
+		steps, err = db.MigrateDown(ctx, operation.count, opts...)
+	case "to":
+		steps, err = db.MigrateTo(ctx, operation.target, opts...)
+	case "redo":
+		steps, err = db.MigrateRedo(ctx, opts...)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to migrate: %w", err)
+	}
+
+	a.printMigrationSteps(dbName, steps, operation.dryRun)
+
+	return nil
+}
+
+func (a *Application) printMigrationStatus(dbName string, statuses []database.MigrationStatus) {
+	w := tabwriter.NewWriter(a.output, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "Database %s:\n", dbName)
+	fmt.Fprintln(w, "  REPOSITORY\tMIGRATION\tSTATUS\tAPPLIED AT")
+	for _, status := range statuses {
+		state, appliedAt := "pending", "-"
+		if status.Applied {
+			state, appliedAt = "applied", status.AppliedAt.Format(time.RFC3339)
+		}
+		if status.Changed {
+			state = "changed"
+		}
+		if status.Missing {
+			state = "missing"
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", status.Repository, status.ID, state, appliedAt)
+	}
+
+	_ = w.Flush()
+}
+
+func (a *Application) printMigrationSteps(dbName string, steps []database.MigrationStep, dryRun bool) {
+	prefix := ""
+	if dryRun {
+		prefix = "(dry run) "
+	}
+
+	if len(steps) == 0 {
+		fmt.Fprintf(a.output, "%sDatabase %s: nothing to migrate\n", prefix, dbName)
+		return
+	}
+
+	fmt.Fprintf(a.output, "%sDatabase %s:\n", prefix, dbName)
+	for _, step := range steps {
+		fmt.Fprintf(a.output, "  %s\n", step)
+	}
+}
@@ -0,0 +1,75 @@
+package application_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/platforma-dev/platforma/application"
+	"github.com/platforma-dev/platforma/database"
+)
+
+type migrationsRepo struct {
+	migrations []database.Migration
+}
+
+func (r migrationsRepo) Migrations() []database.Migration {
+	return r.migrations
+}
+
+func TestMigrateDrift(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dsn := filepath.Join(t.TempDir(), "test.db")
+
+	newApp := func(t *testing.T, up string, opts ...database.MigrateOption) *application.Application {
+		t.Helper()
+
+		db, err := database.New(dsn, database.WithDialect(database.SQLite{}))
+		if err != nil {
+			t.Fatalf("failed to initialize database: %s", err.Error())
+		}
+		t.Cleanup(func() { _ = db.Close() })
+
+		app := application.New(application.WithOutput(&bytes.Buffer{}))
+		app.RegisterDatabase("main", db, opts...)
+		app.RegisterRepository("main", "items", migrationsRepo{migrations: []database.Migration{{ID: "init", Up: up}}})
+		app.RegisterCommandFunc("noop", func(_ context.Context, _ []string) error {
+			return nil
+		}, application.CommandConfig{Databases: []string{"main"}})
+
+		return app
+	}
+
+	err := newApp(t, "CREATE TABLE items (id TEXT)").Execute(ctx, []string{"migrate"})
+	if err != nil {
+		t.Fatalf("failed to migrate: %s", err.Error())
+	}
+
+	// The applied migration is edited afterwards
+	const editedUp = "CREATE TABLE items (id TEXT PRIMARY KEY)"
+
+	err = newApp(t, editedUp).Execute(ctx, []string{"migrate"})
+	if !errors.Is(err, database.ErrMigrationChanged) {
+		t.Fatalf("expected ErrMigrationChanged, got %v", err)
+	}
+
+	err = newApp(t, editedUp).Execute(ctx, []string{"migrate", "-allow-drift"})
+	if err != nil {
+		t.Fatalf("expected drift to be allowed by flag, got %v", err)
+	}
+
+	err = newApp(t, editedUp).Execute(ctx, []string{"noop"})
+	if !errors.Is(err, database.ErrMigrationChanged) {
+		t.Fatalf("expected ErrMigrationChanged on startup, got %v", err)
+	}
+
+	err = newApp(t, editedUp, database.AllowDrift()).Execute(ctx, []string{"noop"})
+	if err != nil {
+		t.Fatalf("expected drift to be allowed by database options, got %v", err)
+	}
+}
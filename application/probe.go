@@ -0,0 +1,181 @@
+package application
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/platforma-dev/platforma/log"
+)
+
+// ErrReadinessCheckTimeout is reported for a service whose readiness check doesn't finish within the health check timeout.
+var ErrReadinessCheckTimeout = errors.New("readiness check timed out")
+
+// ProbeResult is the outcome of a liveness or readiness probe.
+type ProbeResult struct {
+	OK       bool              `json:"ok"`
+	Failures map[string]string `json:"failures,omitempty"` // Failing service names mapped to failure reasons
+}
+
+func newProbeResult() *ProbeResult {
+	return &ProbeResult{OK: true, Failures: make(map[string]string)}
+}
+
+func (r *ProbeResult) fail(serviceName, reason string) {
+	r.OK = false
+	r.Failures[serviceName] = reason
+}
+
+// Liveness reports whether the application is alive.
+// The application is alive unless any of its required services has failed.
+func (a *Application) Liveness(_ context.Context) *ProbeResult {
+	result := newProbeResult()
+
+	for serviceName, service := range a.services {
+		if service.config.optional {
+			continue
+		}
+
+		serviceHealth, ok := a.health.Service(serviceName)
+		if ok && serviceHealth.Status == ServiceStatusFailed {
+			result.fail(serviceName, serviceHealth.Error)
+		}
+	}
+
+	return result
+}
+
+// Readiness reports whether the application is ready to accept traffic.
+// The application is ready when all of its required services are running.
+// Services implementing ReadinessChecker are asked for their readiness on every call, concurrently and
+// within the health check timeout, see WithHealthCheckTimeout. A service whose check times out is not ready.
+func (a *Application) Readiness(ctx context.Context) *ProbeResult {
+	result := newProbeResult()
+	checkers := map[string]ReadinessChecker{}
+
+	for serviceName, service := range a.services {
+		if service.config.optional {
+			continue
+		}
+
+		serviceHealth, ok := a.health.Service(serviceName)
+		if !ok {
+			continue
+		}
+
+		switch serviceHealth.Status {
+		case ServiceStatusRunning:
+			if checker, ok := a.readiness[serviceName]; ok {
+				checkers[serviceName] = checker
+			}
+		case ServiceStatusFailed:
+			result.fail(serviceName, serviceHealth.Error)
+		default:
+			status := strings.ReplaceAll(strings.ToLower(string(serviceHealth.Status)), "_", " ")
+			result.fail(serviceName, "service is "+status)
+		}
+	}
+
+	timeout := cmp.Or(a.healthCheckTimeout, defaultHealthCheckTimeout)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for serviceName, checker := range checkers {
+		wg.Go(func() {
+			err := runReadinessCheck(ctx, checker, timeout)
+			if err != nil {
+				mu.Lock()
+				result.fail(serviceName, err.Error())
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+
+	return result
+}
+
+// runReadinessCheck asks the checker for readiness. It returns an error when the check
+// doesn't finish in time, even if the checker ignores its context.
+func runReadinessCheck(ctx context.Context, checker ReadinessChecker, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	errs := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				errs <- &ErrServicePanicked{Value: r, Stack: debug.Stack()}
+			}
+		}()
+
+		errs <- checker.Readiness(ctx)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("%w after %s", ErrReadinessCheckTimeout, timeout)
+	}
+}
+
+type livenessProber interface {
+	Liveness(context.Context) *ProbeResult
+}
+
+type readinessProber interface {
+	Readiness(context.Context) *ProbeResult
+}
+
+// LivenessHandler is an HTTP handler for Kubernetes-style liveness probes.
+// It responds with 200 when the application is alive and 503 otherwise.
+type LivenessHandler struct {
+	app livenessProber
+}
+
+// NewLivenessHandler creates a new LivenessHandler for the given application.
+func NewLivenessHandler(app livenessProber) *LivenessHandler {
+	return &LivenessHandler{app: app}
+}
+
+func (h *LivenessHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	writeProbeResult(w, r, h.app.Liveness(r.Context()))
+}
+
+// ReadinessHandler is an HTTP handler for Kubernetes-style readiness probes.
+// It responds with 200 when the application is ready and 503 otherwise.
+type ReadinessHandler struct {
+	app readinessProber
+}
+
+// NewReadinessHandler creates a new ReadinessHandler for the given application.
+func NewReadinessHandler(app readinessProber) *ReadinessHandler {
+	return &ReadinessHandler{app: app}
+}
+
+func (h *ReadinessHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	writeProbeResult(w, r, h.app.Readiness(r.Context()))
+}
+
+func writeProbeResult(w http.ResponseWriter, r *http.Request, result *ProbeResult) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if result.OK {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	err := json.NewEncoder(w).Encode(result)
+	if err != nil {
+		log.ErrorContext(r.Context(), "failed to decode response to json", "error", err)
+	}
+}
@@ -0,0 +1,156 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/platforma-dev/platforma/application"
+)
+
+func TestProbes(t *testing.T) {
+	t.Parallel()
+
+	t.Run("not ready before start", func(t *testing.T) {
+		t.Parallel()
+
+		app := application.New()
+		app.RegisterService("api", &mockService{})
+
+		w := httptest.NewRecorder()
+		application.NewReadinessHandler(app).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Fatalf("expected status 503, got %d", w.Code)
+		}
+
+		w = httptest.NewRecorder()
+		application.NewLivenessHandler(app).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/live", nil))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", w.Code)
+		}
+	})
+
+	t.Run("ready and alive while running", func(t *testing.T) {
+		t.Parallel()
+
+		app := application.New()
+		app.RegisterService("api", &mockService{})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go app.Run(ctx)
+
+		time.Sleep(50 * time.Millisecond)
+
+		if result := app.Readiness(ctx); !result.OK {
+			t.Fatalf("expected application to be ready, got failures %v", result.Failures)
+		}
+
+		if result := app.Liveness(ctx); !result.OK {
+			t.Fatalf("expected application to be alive, got failures %v", result.Failures)
+		}
+	})
+
+	t.Run("failed required service", func(t *testing.T) {
+		t.Parallel()
+
+		app := application.New()
+		app.RegisterService("api", &mockService{runErr: errors.New("boom")})
+
+		err := app.Run(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		result := app.Liveness(context.Background())
+		if result.OK {
+			t.Fatal("expected application not to be alive")
+		}
+
+		if result.Failures["api"] != "boom" {
+			t.Fatalf("expected failure reason boom, got %q", result.Failures["api"])
+		}
+	})
+
+	t.Run("failed optional service", func(t *testing.T) {
+		t.Parallel()
+
+		app := application.New()
+		app.RegisterService("api", &mockService{runErr: errors.New("boom")}, application.Optional())
+
+		err := app.Run(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if result := app.Liveness(context.Background()); !result.OK {
+			t.Fatalf("expected application to be alive, got failures %v", result.Failures)
+		}
+	})
+
+	t.Run("readiness checker", func(t *testing.T) {
+		t.Parallel()
+
+		app := application.New()
+		app.RegisterService("api", &mockReadinessService{readinessErr: errors.New("warming up")})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go app.Run(ctx)
+
+		time.Sleep(50 * time.Millisecond)
+
+		result := app.Readiness(ctx)
+		if result.OK {
+			t.Fatal("expected application not to be ready")
+		}
+
+		if result.Failures["api"] != "warming up" {
+			t.Fatalf("expected failure reason warming up, got %q", result.Failures["api"])
+		}
+	})
+
+	t.Run("readiness checker times out", func(t *testing.T) {
+		t.Parallel()
+
+		app := application.New(application.WithHealthCheckTimeout(20 * time.Millisecond))
+		app.RegisterService("api", &mockReadinessService{readinessDelay: time.Second})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go app.Run(ctx)
+
+		time.Sleep(50 * time.Millisecond)
+
+		start := time.Now()
+		result := app.Readiness(ctx)
+		if time.Since(start) > 500*time.Millisecond {
+			t.Fatalf("expected readiness to return after timeout, took %s", time.Since(start))
+		}
+
+		if result.OK || !strings.HasPrefix(result.Failures["api"], "readiness check timed out") {
+			t.Fatalf("expected readiness check timeout, got %+v", result)
+		}
+	})
+}
+
+type mockReadinessService struct {
+	readinessErr   error
+	readinessDelay time.Duration // Readiness ignores its context for this long
+}
+
+func (s *mockReadinessService) Run(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+func (s *mockReadinessService) Readiness(_ context.Context) error {
+	time.Sleep(s.readinessDelay)
+	return s.readinessErr
+}
@@ -0,0 +1,85 @@
+package application
+
+import "time"
+
+const (
+	defaultRestartInitialBackoff = time.Second
+	defaultRestartMaxBackoff     = 30 * time.Second
+)
+
+// RestartMode defines when a stopped service is restarted.
+type RestartMode string
+
+const (
+	// RestartNever never restarts the service.
+	RestartNever RestartMode = "never"
+	// RestartAlways restarts the service whenever it stops, unless the application is shutting down.
+	RestartAlways RestartMode = "always"
+	// RestartOnFailure restarts the service only if it returned an error or panicked.
+	RestartOnFailure RestartMode = "on-failure"
+)
+
+// RestartPolicy configures how a service is restarted after it stops.
+type RestartPolicy struct {
+	Mode           RestartMode   // When the service should be restarted
+	MaxAttempts    int           // Maximum number of restarts, zero means no limit
+	InitialBackoff time.Duration // Delay before the first restart, defaults to 1 second
+	MaxBackoff     time.Duration // Upper limit for the delay between restarts, defaults to 30 seconds
+}
+
+// shouldRestart reports whether the service should be restarted after its run ended with err
+// and it has already been restarted the given number of times.
+func (p RestartPolicy) shouldRestart(err error, restarts int) bool {
+	if p.MaxAttempts > 0 && restarts >= p.MaxAttempts {
+		return false
+	}
+
+	switch p.Mode {
+	case RestartAlways:
+		return true
+	case RestartOnFailure:
+		return err != nil
+	default:
+		return false
+	}
+}
+
+// backoff returns the delay before the restart with the given zero-based index.
+// The delay doubles with every restart until it reaches MaxBackoff.
+func (p RestartPolicy) backoff(restart int) time.Duration {
+	delay := p.InitialBackoff
+	if delay <= 0 {
+		delay = defaultRestartInitialBackoff
+	}
+
+	maxDelay := p.MaxBackoff
+	if maxDelay <= 0 {
+		maxDelay = defaultRestartMaxBackoff
+	}
+
+	for range restart {
+		delay *= 2
+		if delay >= maxDelay {
+			return maxDelay
+		}
+	}
+
+	return min(delay, maxDelay)
+}
+
+// WithRestartPolicy sets the restart policy for the service.
+// By default services are never restarted.
+func WithRestartPolicy(policy RestartPolicy) ServiceOption {
+	return func(c *serviceConfig) {
+		c.restartPolicy = policy
+	}
+}
+
+// Critical marks the service as critical for the application.
+// When a critical service fails and is not going to be restarted anymore, the whole application is stopped
+// and Run returns ErrServiceFailed.
+func Critical() ServiceOption {
+	return func(c *serviceConfig) {
+		c.critical = true
+	}
+}
@@ -0,0 +1,301 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/platforma-dev/platforma/application"
+)
+
+func TestRestartPolicy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("never restart by default", func(t *testing.T) {
+		t.Parallel()
+
+		var runs atomic.Int32
+		app := application.New()
+		app.RegisterService("svc", application.RunnerFunc(func(_ context.Context) error {
+			runs.Add(1)
+			return errors.New("boom")
+		}))
+
+		err := app.Run(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if runs.Load() != 1 {
+			t.Fatalf("expected 1 run, got %d", runs.Load())
+		}
+	})
+
+	t.Run("restart on failure until attempts are exhausted", func(t *testing.T) {
+		t.Parallel()
+
+		var runs atomic.Int32
+		app := application.New()
+		app.RegisterService("svc", application.RunnerFunc(func(_ context.Context) error {
+			runs.Add(1)
+			return errors.New("boom")
+		}), application.WithRestartPolicy(application.RestartPolicy{
+			Mode:           application.RestartOnFailure,
+			MaxAttempts:    2,
+			InitialBackoff: time.Millisecond,
+		}))
+
+		err := app.Run(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if runs.Load() != 3 {
+			t.Fatalf("expected 3 runs, got %d", runs.Load())
+		}
+
+		health := app.Health(context.Background())
+		if health.Services["svc"].Restarts != 2 {
+			t.Fatalf("expected 2 restarts, got %d", health.Services["svc"].Restarts)
+		}
+
+		if health.Services["svc"].LastError != "boom" {
+			t.Fatalf("expected last error boom, got %q", health.Services["svc"].LastError)
+		}
+	})
+
+	t.Run("restart on failure does not restart successful service", func(t *testing.T) {
+		t.Parallel()
+
+		var runs atomic.Int32
+		app := application.New()
+		app.RegisterService("svc", application.RunnerFunc(func(_ context.Context) error {
+			runs.Add(1)
+			return nil
+		}), application.WithRestartPolicy(application.RestartPolicy{
+			Mode:           application.RestartOnFailure,
+			InitialBackoff: time.Millisecond,
+		}))
+
+		err := app.Run(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if runs.Load() != 1 {
+			t.Fatalf("expected 1 run, got %d", runs.Load())
+		}
+	})
+
+	t.Run("always restart", func(t *testing.T) {
+		t.Parallel()
+
+		var runs atomic.Int32
+		app := application.New()
+		app.RegisterService("svc", application.RunnerFunc(func(_ context.Context) error {
+			runs.Add(1)
+			return nil
+		}), application.WithRestartPolicy(application.RestartPolicy{
+			Mode:           application.RestartAlways,
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+		}))
+
+		err := app.Run(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if runs.Load() != 4 {
+			t.Fatalf("expected 4 runs, got %d", runs.Load())
+		}
+	})
+
+	t.Run("restart after panic", func(t *testing.T) {
+		t.Parallel()
+
+		var runs atomic.Int32
+		app := application.New()
+		app.RegisterService("svc", application.RunnerFunc(func(_ context.Context) error {
+			if runs.Add(1) == 1 {
+				panic("boom")
+			}
+			return nil
+		}), application.WithRestartPolicy(application.RestartPolicy{
+			Mode:           application.RestartOnFailure,
+			InitialBackoff: time.Millisecond,
+		}))
+
+		err := app.Run(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if runs.Load() != 2 {
+			t.Fatalf("expected 2 runs, got %d", runs.Load())
+		}
+	})
+
+	t.Run("panic of critical service", func(t *testing.T) {
+		t.Parallel()
+
+		app := application.New()
+		app.RegisterService("critical", application.RunnerFunc(func(_ context.Context) error {
+			panic("boom")
+		}), application.Critical())
+
+		err := app.Run(context.Background())
+
+		var panicErr *application.ErrServicePanicked
+		if !errors.As(err, &panicErr) {
+			t.Fatalf("expected ErrServicePanicked, got %v", err)
+		}
+
+		if panicErr.Value != "boom" || !strings.Contains(string(panicErr.Stack), "restart_test.go") {
+			t.Fatalf("expected panic value and stack, got %v\n%s", panicErr.Value, panicErr.Stack)
+		}
+
+		svc := app.Health(context.Background()).Services["critical"]
+		if svc.Status != application.ServiceStatusFailed || svc.Error != "service panicked: boom" {
+			t.Fatalf("expected service to be failed by panic, got %+v", svc)
+		}
+	})
+
+	t.Run("critical service stops application", func(t *testing.T) {
+		t.Parallel()
+
+		app := application.New()
+		app.RegisterService("long", &mockService{})
+		app.RegisterService("critical", application.RunnerFunc(func(_ context.Context) error {
+			return errors.New("boom")
+		}), application.Critical(), application.WithRestartPolicy(application.RestartPolicy{
+			Mode:           application.RestartOnFailure,
+			MaxAttempts:    1,
+			InitialBackoff: time.Millisecond,
+		}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		err := app.Run(ctx)
+
+		if ctx.Err() != nil {
+			t.Fatal("expected application to stop before timeout")
+		}
+
+		var serviceErr *application.ErrServiceFailed
+		if !errors.As(err, &serviceErr) {
+			t.Fatalf("expected service failed error, got %v", err)
+		}
+
+		if serviceErr.ServiceName() != "critical" {
+			t.Fatalf("expected failed service to be critical, got %s", serviceErr.ServiceName())
+		}
+	})
+
+	t.Run("critical service failure wraps cause", func(t *testing.T) {
+		t.Parallel()
+
+		someErr := errors.New("boom")
+		app := application.New()
+		app.RegisterService("long", &mockService{})
+		app.RegisterService("critical", application.RunnerFunc(func(_ context.Context) error {
+			return someErr
+		}), application.Critical())
+
+		err := app.Run(context.Background())
+		if !errors.Is(err, someErr) {
+			t.Fatalf("expected error to wrap cause, got %v", err)
+		}
+	})
+
+	t.Run("non-critical service failure is not returned", func(t *testing.T) {
+		t.Parallel()
+
+		app := application.New()
+		app.RegisterService("svc", application.RunnerFunc(func(_ context.Context) error {
+			return errors.New("boom")
+		}))
+
+		err := app.Run(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+}
+
+func TestRestartService(t *testing.T) {
+	t.Parallel()
+
+	t.Run("restart running service", func(t *testing.T) {
+		t.Parallel()
+
+		var runs atomic.Int32
+		app := application.New()
+		app.RegisterService("svc", application.RunnerFunc(func(ctx context.Context) error {
+			runs.Add(1)
+			<-ctx.Done()
+			return ctx.Err()
+		}))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go app.Run(ctx)
+
+		time.Sleep(20 * time.Millisecond)
+
+		err := app.RestartService("svc")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		time.Sleep(20 * time.Millisecond)
+
+		if runs.Load() != 2 {
+			t.Fatalf("expected 2 runs, got %d", runs.Load())
+		}
+
+		svc := app.Health(ctx).Services["svc"]
+		if svc.Status != application.ServiceStatusRunning || svc.Restarts != 1 {
+			t.Fatalf("expected running service with 1 restart, got %+v", svc)
+		}
+	})
+
+	t.Run("unknown service", func(t *testing.T) {
+		t.Parallel()
+
+		app := application.New()
+
+		err := app.RestartService("svc")
+		if !errors.Is(err, application.ErrUnknownService) {
+			t.Fatalf("expected ErrUnknownService, got %v", err)
+		}
+	})
+
+	t.Run("service is not running", func(t *testing.T) {
+		t.Parallel()
+
+		app := application.New()
+		app.RegisterService("svc", application.RunnerFunc(func(_ context.Context) error {
+			return nil
+		}))
+
+		err := app.RestartService("svc")
+		if !errors.Is(err, application.ErrServiceNotRunning) {
+			t.Fatalf("expected ErrServiceNotRunning before run, got %v", err)
+		}
+
+		err = app.Run(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		err = app.RestartService("svc")
+		if !errors.Is(err, application.ErrServiceNotRunning) {
+			t.Fatalf("expected ErrServiceNotRunning after run, got %v", err)
+		}
+	})
+}
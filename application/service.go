@@ -0,0 +1,269 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ErrServiceDependencyCycle is returned by Run when service dependencies form a cycle.
+var ErrServiceDependencyCycle = errors.New("service dependency cycle")
+
+// ErrUnknownServiceDependency is returned by Run when a service depends on a service that is not registered.
+var ErrUnknownServiceDependency = errors.New("unknown service dependency")
+
+// ErrServiceDependencyStopped is reported for a service that was not started
+// because its dependency stopped before becoming ready.
+var ErrServiceDependencyStopped = errors.New("service dependency stopped before becoming ready")
+
+// ErrUnknownService is returned when an operation refers to a service that is not registered.
+var ErrUnknownService = errors.New("unknown service")
+
+// ErrServiceNotRunning is returned by RestartService when the service is not running.
+var ErrServiceNotRunning = errors.New("service is not running")
+
+// ErrServicePanicked is returned by a service run that panicked. It carries the panic value and
+// the stack trace of the panicking goroutine. It is also used by queue workers and scheduler runs.
+type ErrServicePanicked struct {
+	Value any    // Value passed to panic
+	Stack []byte // Stack trace captured when the panic was recovered
+}
+
+// Error returns the formatted error message for ErrServicePanicked.
+func (e *ErrServicePanicked) Error() string {
+	return fmt.Sprintf("service panicked: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *ErrServicePanicked) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// ErrServiceFailed is returned by Run when a critical service fails and is not restarted anymore.
+type ErrServiceFailed struct {
+	serviceName string
+	err         error
+}
+
+// Error returns the formatted error message for ErrServiceFailed.
+func (e *ErrServiceFailed) Error() string {
+	return fmt.Sprintf("service %s failed: %v", e.serviceName, e.err)
+}
+
+// Unwrap returns the underlying error for ErrServiceFailed.
+func (e *ErrServiceFailed) Unwrap() error {
+	return e.err
+}
+
+// ServiceName returns the name of the failed service.
+func (e *ErrServiceFailed) ServiceName() string {
+	return e.serviceName
+}
+
+// Readier is implemented by services that need some time after Run is called
+// before their dependents can use them.
+type Readier interface {
+	// Ready returns a channel that is closed once the service is ready.
+	Ready() <-chan struct{}
+}
+
+// ServiceOption configures how a registered service is run by the application.
+type ServiceOption func(*serviceConfig)
+
+// DependsOn declares that the service must be started after the named services are ready
+// and stopped before them.
+func DependsOn(serviceNames ...string) ServiceOption {
+	return func(c *serviceConfig) {
+		c.dependsOn = append(c.dependsOn, serviceNames...)
+	}
+}
+
+// Optional marks the service as not required for the application to be alive and ready.
+// Failures of optional services are still reported in health but do not fail liveness and readiness probes.
+func Optional() ServiceOption {
+	return func(c *serviceConfig) {
+		c.optional = true
+	}
+}
+
+type serviceConfig struct {
+	dependsOn     []string
+	optional      bool
+	critical      bool
+	restartPolicy RestartPolicy
+}
+
+// ServiceInfo describes a registered service.
+type ServiceInfo struct {
+	Name      string   `json:"name"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+	Optional  bool     `json:"optional"`
+	Critical  bool     `json:"critical"`
+	Runner    Runner   `json:"-"`
+}
+
+// service is a registered service with its configuration.
+type service struct {
+	runner Runner
+	config serviceConfig
+}
+
+// applicationFailure holds the first error that caused the application to stop.
+type applicationFailure struct {
+	mu  sync.Mutex
+	err error
+}
+
+func (f *applicationFailure) set(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err == nil {
+		f.err = err
+	}
+}
+
+func (f *applicationFailure) get() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.err
+}
+
+// serviceState tracks a running service. ready is closed once the service is ready
+// to be used by dependents and done is closed once the service goroutine has exited.
+type serviceState struct {
+	cancel    context.CancelFunc
+	ready     chan struct{}
+	markReady func()
+	done      chan struct{}
+
+	mu               sync.Mutex
+	cancelRun        context.CancelFunc // Cancels the current run of the service, nil between runs
+	restartRequested bool
+}
+
+func newServiceState(cancel context.CancelFunc) *serviceState {
+	ready := make(chan struct{})
+	return &serviceState{
+		cancel:    cancel,
+		ready:     ready,
+		markReady: sync.OnceFunc(func() { close(ready) }),
+		done:      make(chan struct{}),
+	}
+}
+
+// startRun returns a context for a single run of the service that is cancelled on restart request.
+func (s *serviceState) startRun(ctx context.Context) context.Context {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runCtx, cancelRun := context.WithCancel(ctx)
+	s.cancelRun = cancelRun
+
+	return runCtx
+}
+
+// finishRun releases the context of the current run and reports whether a restart was requested.
+func (s *serviceState) finishRun() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancelRun != nil {
+		s.cancelRun()
+		s.cancelRun = nil
+	}
+
+	restartRequested := s.restartRequested
+	s.restartRequested = false
+
+	return restartRequested
+}
+
+// requestRestart cancels the current run of the service so it is started again.
+// beforeCancel is called right before the run is cancelled.
+// It returns false if the service is not running.
+func (s *serviceState) requestRestart(beforeCancel func()) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancelRun == nil {
+		return false
+	}
+
+	s.restartRequested = true
+	beforeCancel()
+	s.cancelRun()
+
+	return true
+}
+
+// sortServices returns service names in dependency order: every service comes after all of its dependencies.
+// Services without dependencies between them are ordered by name so the result is deterministic.
+func sortServices(services map[string]*service) ([]string, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	marks := make(map[string]int, len(services))
+	order := make([]string, 0, len(services))
+	path := []string{}
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch marks[name] {
+		case visited:
+			return nil
+		case visiting:
+			cycleStart := slices.Index(path, name)
+			cycle := append(slices.Clone(path[cycleStart:]), name)
+			return fmt.Errorf("%w: %s", ErrServiceDependencyCycle, strings.Join(cycle, " -> "))
+		}
+
+		marks[name] = visiting
+		path = append(path, name)
+
+		deps := slices.Clone(services[name].config.dependsOn)
+		slices.Sort(deps)
+
+		for _, dep := range deps {
+			if _, ok := services[dep]; !ok {
+				return fmt.Errorf("%w: service %q depends on %q", ErrUnknownServiceDependency, name, dep)
+			}
+
+			err := visit(dep)
+			if err != nil {
+				return err
+			}
+		}
+
+		path = path[:len(path)-1]
+		marks[name] = visited
+		order = append(order, name)
+
+		return nil
+	}
+
+	for _, name := range names {
+		err := visit(name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
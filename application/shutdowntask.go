@@ -0,0 +1,34 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrShutdownTimeout is returned by Run when the application does not shut down within the shutdown timeout.
+var ErrShutdownTimeout = errors.New("shutdown timeout exceeded")
+
+var errShutdownTaskPanicked = errors.New("shutdown task panicked")
+
+// ShutdownTaskConfig contains configuration options for a shutdown task.
+type ShutdownTaskConfig struct {
+	Name string // Name of the shutdown task
+}
+
+// shutdownTask represents an individual shutdown task with its runner and configuration.
+type shutdownTask struct {
+	runner Runner
+	config ShutdownTaskConfig
+}
+
+// runShutdownTask runs the shutdown task and converts its panic into an error.
+func runShutdownTask(ctx context.Context, task shutdownTask) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", errShutdownTaskPanicked, r)
+		}
+	}()
+
+	return task.runner.Run(ctx)
+}
@@ -0,0 +1,183 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/platforma-dev/platforma/application"
+)
+
+func TestShutdown(t *testing.T) {
+	t.Parallel()
+
+	t.Run("shutdown tasks run after services in reverse order", func(t *testing.T) {
+		t.Parallel()
+
+		events := &eventLog{}
+		app := application.New()
+		app.RegisterService("api", &mockService{name: "api", events: events})
+
+		app.OnStopFunc(func(_ context.Context) error {
+			events.add("first task")
+			return nil
+		}, application.ShutdownTaskConfig{Name: "first"})
+
+		app.OnStopFunc(func(_ context.Context) error {
+			events.add("second task")
+			return errors.New("some error")
+		}, application.ShutdownTaskConfig{Name: "second"})
+
+		app.OnStopFunc(func(_ context.Context) error {
+			panic("boom")
+		}, application.ShutdownTaskConfig{Name: "third"})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			cancel()
+		}()
+
+		err := app.Run(ctx)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		expected := []string{"start api", "stop api", "second task", "first task"}
+		if got := events.get(); !slices.Equal(got, expected) {
+			t.Fatalf("expected events %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("service exceeds shutdown timeout", func(t *testing.T) {
+		t.Parallel()
+
+		app := application.New(application.WithShutdownTimeout(50 * time.Millisecond))
+		app.RegisterService("slow", application.RunnerFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			time.Sleep(time.Second)
+			return nil
+		}))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			cancel()
+		}()
+
+		start := time.Now()
+		err := app.Run(ctx)
+		if !errors.Is(err, application.ErrShutdownTimeout) {
+			t.Fatalf("expected shutdown timeout error, got %v", err)
+		}
+
+		if time.Since(start) > 500*time.Millisecond {
+			t.Fatalf("expected Run to return after shutdown timeout, took %s", time.Since(start))
+		}
+	})
+
+	t.Run("shutdown task exceeds shutdown timeout", func(t *testing.T) {
+		t.Parallel()
+
+		app := application.New(application.WithShutdownTimeout(50 * time.Millisecond))
+		app.OnStopFunc(func(_ context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		}, application.ShutdownTaskConfig{Name: "slow"})
+
+		err := app.Run(context.Background())
+		if !errors.Is(err, application.ErrShutdownTimeout) {
+			t.Fatalf("expected shutdown timeout error, got %v", err)
+		}
+	})
+	t.Run("shutdown tasks run after startup failure", func(t *testing.T) {
+		t.Parallel()
+
+		startErr := errors.New("startup failed")
+		events := &eventLog{}
+		app := application.New()
+		app.OnStartFunc(func(_ context.Context) error {
+			return startErr
+		}, application.StartupTaskConfig{Name: "failing", AbortOnError: true})
+
+		app.OnStopFunc(func(_ context.Context) error {
+			events.add("task")
+			return nil
+		}, application.ShutdownTaskConfig{Name: "task"})
+
+		err := app.Run(context.Background())
+		if !errors.Is(err, startErr) {
+			t.Fatalf("expected startup error, got %v", err)
+		}
+
+		if got := events.get(); !slices.Equal(got, []string{"task"}) {
+			t.Fatalf("expected shutdown task to run, got %v", got)
+		}
+	})
+
+	t.Run("shutdown tasks run after command startup failure", func(t *testing.T) {
+		t.Parallel()
+
+		startErr := errors.New("startup failed")
+		events := &eventLog{}
+		app := application.New()
+		app.OnStartFunc(func(_ context.Context) error {
+			return startErr
+		}, application.StartupTaskConfig{Name: "failing", AbortOnError: true})
+
+		app.OnStopFunc(func(_ context.Context) error {
+			events.add("task")
+			return nil
+		}, application.ShutdownTaskConfig{Name: "task"})
+
+		app.RegisterCommandFunc("seed", func(_ context.Context, _ []string) error {
+			events.add("seed")
+			return nil
+		}, application.CommandConfig{StartupTasks: true})
+
+		err := app.Execute(context.Background(), []string{"seed"})
+		if !errors.Is(err, startErr) {
+			t.Fatalf("expected startup error, got %v", err)
+		}
+
+		if got := events.get(); !slices.Equal(got, []string{"task"}) {
+			t.Fatalf("expected shutdown task to run without the command, got %v", got)
+		}
+	})
+
+	t.Run("shutdown tasks run after service exceeds shutdown timeout", func(t *testing.T) {
+		t.Parallel()
+
+		app := application.New(application.WithShutdownTimeout(50 * time.Millisecond))
+		app.RegisterService("slow", application.RunnerFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			time.Sleep(time.Second)
+			return nil
+		}))
+
+		taskRan := make(chan struct{})
+		app.OnStopFunc(func(_ context.Context) error {
+			close(taskRan)
+			return nil
+		}, application.ShutdownTaskConfig{Name: "task"})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			cancel()
+		}()
+
+		err := app.Run(ctx)
+		if !errors.Is(err, application.ErrShutdownTimeout) {
+			t.Fatalf("expected shutdown timeout error, got %v", err)
+		}
+
+		select {
+		case <-taskRan:
+		case <-time.After(time.Second):
+			t.Fatalf("expected shutdown task to run after shutdown timeout")
+		}
+	})
+}
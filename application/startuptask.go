@@ -1,6 +1,17 @@
 package application
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/platforma-dev/platforma/log"
+)
+
+// ErrStartupTaskTimeout is returned when a startup task attempt does not finish within its timeout.
+var ErrStartupTaskTimeout = errors.New("startup task timed out")
 
 // ErrStartupTaskFailed represents an error that occurs when a startup task fails.
 type ErrStartupTaskFailed struct {
@@ -19,8 +30,13 @@ func (e *ErrStartupTaskFailed) Unwrap() error {
 
 // StartupTaskConfig contains configuration options for a startup task.
 type StartupTaskConfig struct {
-	Name         string // Name of the startup task
-	AbortOnError bool   // Whether to abort application startup if this task fails
+	Name            string        // Name of the startup task
+	AbortOnError    bool          // Whether to abort application startup if this task fails
+	Timeout         time.Duration // Maximum duration of a single attempt, no limit by default
+	Retries         int           // Number of retries after a failed attempt
+	RetryBackoff    time.Duration // Delay before the first retry, defaults to 1 second
+	MaxRetryBackoff time.Duration // Upper limit for the delay between retries, defaults to 30 seconds
+	Group           string        // Consecutive tasks with the same non-empty group run concurrently
 }
 
 // startupTask represents an individual startup task with its runner and configuration.
@@ -28,3 +44,141 @@ type startupTask struct {
 	runner Runner
 	config StartupTaskConfig
 }
+
+// startupStages splits startup tasks into stages that run one after another.
+// Consecutive tasks of the same group form a single stage, other tasks get a stage of their own.
+// Stages hold indexes of the tasks.
+func startupStages(tasks []startupTask) [][]int {
+	stages := [][]int{}
+
+	for i, task := range tasks {
+		last := len(stages) - 1
+		if task.config.Group != "" && last >= 0 && tasks[stages[last][0]].config.Group == task.config.Group {
+			stages[last] = append(stages[last], i)
+			continue
+		}
+
+		stages = append(stages, []int{i})
+	}
+
+	return stages
+}
+
+// runStartupTasks runs startup tasks stage by stage.
+// It stops at the first stage where a task configured to abort on error fails.
+func (a *Application) runStartupTasks(ctx context.Context) error {
+	for _, stage := range startupStages(a.startupTasks) {
+		err := a.runStartupStage(ctx, stage)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runStartupStage runs tasks of the stage concurrently and waits for all of them.
+// If a task configured to abort on error fails, other tasks of the stage are cancelled.
+func (a *Application) runStartupStage(ctx context.Context, stage []int) error {
+	stageCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	failure := &applicationFailure{}
+
+	var wg sync.WaitGroup
+	for _, i := range stage {
+		wg.Go(func() {
+			err := a.runStartupTask(stageCtx, i)
+			if err != nil && a.startupTasks[i].config.AbortOnError {
+				failure.set(&ErrStartupTaskFailed{err: err})
+				cancel()
+			}
+		})
+	}
+	wg.Wait()
+
+	return failure.get()
+}
+
+// runStartupTask runs the task with the given index, retrying failed attempts according to its configuration.
+func (a *Application) runStartupTask(ctx context.Context, i int) error {
+	task := a.startupTasks[i]
+	backoff := RestartPolicy{InitialBackoff: task.config.RetryBackoff, MaxBackoff: task.config.MaxRetryBackoff}
+
+	log.InfoContext(ctx, "running task", "task", task.config.Name, "index", i)
+
+	taskCtx := context.WithValue(ctx, log.StartupTaskKey, task.config.Name)
+
+	startedAt := time.Now()
+
+	var err error
+	attempt := 0
+attempts:
+	for ; ; attempt++ {
+		a.health.StartStartupTask(i)
+		a.emit(taskCtx, StartupTaskStarted{EventTime: newEventTime(), Name: task.config.Name, Index: i, Attempt: attempt + 1})
+
+		err = runStartupTaskAttempt(taskCtx, task.runner, task.config.Timeout)
+		if err == nil {
+			break
+		}
+
+		retry := attempt < task.config.Retries && ctx.Err() == nil
+		a.emit(taskCtx, StartupTaskFailed{EventTime: newEventTime(), Name: task.config.Name, Index: i, Attempt: attempt + 1, Err: err, Retry: retry})
+		if !retry {
+			break
+		}
+
+		a.health.FailStartupTaskAttempt(i, err)
+
+		delay := backoff.backoff(attempt)
+		log.WarnContext(taskCtx, "startup task failed, retrying", "error", err, "task", task.config.Name, "attempt", attempt+1, "delay", delay)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			// The application is stopping, so the task fails with the error of the last attempt
+			break attempts
+		}
+	}
+
+	a.health.FinishStartupTask(i, err)
+
+	if err != nil {
+		log.ErrorContext(ctx, "error in startup task", "error", err, "task", task.config.Name)
+		return err
+	}
+
+	a.emit(taskCtx, StartupTaskFinished{EventTime: newEventTime(), Name: task.config.Name, Index: i, Attempts: attempt + 1, Duration: time.Since(startedAt)})
+
+	return nil
+}
+
+// runStartupTaskAttempt runs the task once. If timeout is set, the attempt fails with
+// ErrStartupTaskTimeout when it does not finish in time, even if the task ignores its context.
+func runStartupTaskAttempt(ctx context.Context, runner Runner, timeout time.Duration) error {
+	if timeout <= 0 {
+		return runner.Run(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result := make(chan error, 1)
+	go func() {
+		result <- runner.Run(ctx)
+	}()
+
+	select {
+	case err := <-result:
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%w after %s: %w", ErrStartupTaskTimeout, timeout, err)
+		}
+		return err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%w after %s", ErrStartupTaskTimeout, timeout)
+		}
+		return fmt.Errorf("startup task cancelled: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,182 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/platforma-dev/platforma/application"
+)
+
+func TestStartupTasks(t *testing.T) {
+	t.Parallel()
+
+	t.Run("timeout", func(t *testing.T) {
+		t.Parallel()
+
+		app := application.New()
+		app.OnStartFunc(func(_ context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		}, application.StartupTaskConfig{Name: "slow", AbortOnError: true, Timeout: 10 * time.Millisecond})
+
+		start := time.Now()
+		err := app.Run(context.Background())
+
+		if !errors.Is(err, application.ErrStartupTaskTimeout) {
+			t.Fatalf("expected ErrStartupTaskTimeout, got %v", err)
+		}
+
+		if time.Since(start) > 500*time.Millisecond {
+			t.Fatalf("expected startup task to be abandoned after timeout, took %s", time.Since(start))
+		}
+	})
+
+	t.Run("retry until success", func(t *testing.T) {
+		t.Parallel()
+
+		var attempts atomic.Int32
+		app := application.New()
+		app.OnStartFunc(func(_ context.Context) error {
+			if attempts.Add(1) < 3 {
+				return errors.New("database is not up yet")
+			}
+			return nil
+		}, application.StartupTaskConfig{Name: "wait for db", AbortOnError: true, Retries: 3, RetryBackoff: time.Millisecond})
+
+		err := app.Run(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		task := app.Health(context.Background()).StartupTasks[0]
+		if task.Status != application.StartupTaskStatusSucceeded || task.Attempts != 3 {
+			t.Fatalf("expected task to succeed on 3rd attempt, got %+v", task)
+		}
+
+		if task.LastError != "database is not up yet" || task.Error != "" || task.Duration <= 0 {
+			t.Fatalf("unexpected task health: %+v", task)
+		}
+	})
+
+	t.Run("retries are exhausted", func(t *testing.T) {
+		t.Parallel()
+
+		var attempts atomic.Int32
+		app := application.New()
+		app.OnStartFunc(func(_ context.Context) error {
+			attempts.Add(1)
+			return errors.New("boom")
+		}, application.StartupTaskConfig{Name: "task", AbortOnError: true, Retries: 2, RetryBackoff: time.Millisecond})
+
+		err := app.Run(context.Background())
+
+		var taskErr *application.ErrStartupTaskFailed
+		if !errors.As(err, &taskErr) {
+			t.Fatalf("expected ErrStartupTaskFailed, got %v", err)
+		}
+
+		if attempts.Load() != 3 {
+			t.Fatalf("expected 3 attempts, got %d", attempts.Load())
+		}
+	})
+
+	t.Run("cancellation during backoff stops retries", func(t *testing.T) {
+		t.Parallel()
+
+		var attempts atomic.Int32
+		app := application.New()
+		app.OnStartFunc(func(_ context.Context) error {
+			attempts.Add(1)
+			return errors.New("boom")
+		}, application.StartupTaskConfig{Name: "task", AbortOnError: true, Retries: 2, RetryBackoff: time.Hour})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			cancel()
+		}()
+
+		err := app.Run(ctx)
+		if err == nil || err.Error() != "failed to run startup task: boom" {
+			t.Fatalf("expected error of the last attempt, got %v", err)
+		}
+
+		if attempts.Load() != 1 {
+			t.Fatalf("expected no attempts after cancellation, got %d attempts", attempts.Load())
+		}
+
+		if task := app.Health(context.Background()).StartupTasks[0]; task.Attempts != 1 {
+			t.Fatalf("expected 1 attempt in health, got %+v", task)
+		}
+	})
+
+	t.Run("group runs concurrently", func(t *testing.T) {
+		t.Parallel()
+
+		events := &eventLog{}
+		release := make(chan struct{})
+		var started atomic.Int32
+
+		warmup := func(name string) application.RunnerFunc {
+			return func(_ context.Context) error {
+				if started.Add(1) == 2 {
+					close(release)
+				}
+				<-release
+				events.add(name)
+				return nil
+			}
+		}
+
+		app := application.New()
+		app.OnStartFunc(warmup("cache"), application.StartupTaskConfig{Name: "cache", Group: "warmup", Timeout: time.Second})
+		app.OnStartFunc(warmup("templates"), application.StartupTaskConfig{Name: "templates", Group: "warmup", Timeout: time.Second})
+		app.OnStartFunc(func(_ context.Context) error {
+			events.add("after")
+			return nil
+		}, application.StartupTaskConfig{Name: "after"})
+
+		err := app.Run(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		for _, task := range app.Health(context.Background()).StartupTasks {
+			if task.Status != application.StartupTaskStatusSucceeded {
+				t.Fatalf("expected task %s to succeed, got %+v", task.Name, task)
+			}
+		}
+
+		got := events.get()
+		if len(got) != 3 || got[2] != "after" {
+			t.Fatalf("expected grouped tasks to finish before the next task, got %v", got)
+		}
+	})
+
+	t.Run("abort cancels other tasks of the group", func(t *testing.T) {
+		t.Parallel()
+
+		app := application.New()
+		app.OnStartFunc(func(_ context.Context) error {
+			return errors.New("boom")
+		}, application.StartupTaskConfig{Name: "failing", Group: "warmup", AbortOnError: true})
+		app.OnStartFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}, application.StartupTaskConfig{Name: "waiting", Group: "warmup"})
+
+		err := app.Run(context.Background())
+
+		var taskErr *application.ErrStartupTaskFailed
+		if !errors.As(err, &taskErr) {
+			t.Fatalf("expected ErrStartupTaskFailed, got %v", err)
+		}
+
+		if task := app.Health(context.Background()).StartupTasks[1]; task.Status != application.StartupTaskStatusFailed {
+			t.Fatalf("expected waiting task to be cancelled, got %+v", task)
+		}
+	})
+}
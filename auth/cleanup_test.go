@@ -79,6 +79,50 @@ func TestDeleteUser_EnqueueError_StillSucceeds(t *testing.T) {
 	}
 }
 
+func TestDeleteUser_InTransaction(t *testing.T) {
+	t.Parallel()
+
+	mockRepo := &mockRepository{}
+	mockAuthStorage := &mockAuthStorage{}
+
+	service := auth.NewService(mockRepo, mockAuthStorage, "session", nil, nil, nil)
+
+	user := &auth.User{ID: "test-user-id"}
+	ctx := context.WithValue(context.Background(), auth.UserContextKey, user)
+
+	err := service.DeleteUser(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !mockAuthStorage.deleteSessionsInTx || !mockRepo.deleteInTx {
+		t.Fatal("expected sessions and user to be deleted in transaction")
+	}
+}
+
+func TestDeleteUser_DeleteError_DoesNotEnqueueJob(t *testing.T) {
+	t.Parallel()
+
+	deleteErr := errors.New("connection lost")
+	mockRepo := &mockRepository{deleteErr: deleteErr}
+	mockAuthStorage := &mockAuthStorage{}
+	mockEnqueuer := &mockCleanupEnqueuer{}
+
+	service := auth.NewService(mockRepo, mockAuthStorage, "session", nil, nil, mockEnqueuer)
+
+	user := &auth.User{ID: "test-user-id"}
+	ctx := context.WithValue(context.Background(), auth.UserContextKey, user)
+
+	err := service.DeleteUser(ctx)
+	if !errors.Is(err, deleteErr) {
+		t.Fatalf("expected delete error, got %v", err)
+	}
+
+	if mockEnqueuer.enqueueCalled {
+		t.Fatal("expected Enqueue not to be called")
+	}
+}
+
 type mockCleanupEnqueuer struct {
 	enqueueCalled bool
 	lastJob       auth.UserCleanupJob
@@ -91,9 +135,18 @@ func (m *mockCleanupEnqueuer) Enqueue(_ context.Context, job auth.UserCleanupJob
 	return m.enqueueErr
 }
 
-type mockRepository struct{}
+type txKey struct{}
+
+type mockRepository struct {
+	deleteErr  error
+	deleteInTx bool
+}
+
+func (m *mockRepository) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(context.WithValue(ctx, txKey{}, true))
+}
 
-func (m *mockRepository) Get(_ context.Context, _ string) (*auth.User, error) {
+func (m *mockRepository) Get(_ context.Context, _ any) (*auth.User, error) {
 	return nil, nil
 }
 
@@ -109,11 +162,14 @@ func (m *mockRepository) UpdatePassword(_ context.Context, _, _, _ string) error
 	return nil
 }
 
-func (m *mockRepository) Delete(_ context.Context, _ string) error {
-	return nil
+func (m *mockRepository) Delete(ctx context.Context, _ any) error {
+	m.deleteInTx = ctx.Value(txKey{}) != nil
+	return m.deleteErr
 }
 
-type mockAuthStorage struct{}
+type mockAuthStorage struct {
+	deleteSessionsInTx bool
+}
 
 func (m *mockAuthStorage) GetUserIdFromSessionId(_ context.Context, _ string) (string, error) {
 	return "", nil
@@ -127,6 +183,7 @@ func (m *mockAuthStorage) DeleteSession(_ context.Context, _ string) error {
 	return nil
 }
 
-func (m *mockAuthStorage) DeleteSessionsByUserId(_ context.Context, _ string) error {
+func (m *mockAuthStorage) DeleteSessionsByUserId(ctx context.Context, _ string) error {
+	m.deleteSessionsInTx = ctx.Value(txKey{}) != nil
 	return nil
 }
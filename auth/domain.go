@@ -1,6 +1,9 @@
 package auth
 
 import (
+	"net/http"
+
+	"github.com/platforma-dev/platforma/application"
 	"github.com/platforma-dev/platforma/httpserver"
 )
 
@@ -9,12 +12,30 @@ type Domain struct {
 	Service     *Service
 	HandleGroup *httpserver.HandlerGroup
 	Middleware  httpserver.Middleware
+
+	cleanupEnqueuer cleanupEnqueuer
 }
 
 func (d *Domain) GetRepository() any {
 	return d.Repository
 }
 
+// Routes returns the auth endpoints to be mounted with application.MountOn.
+func (d *Domain) Routes() http.Handler {
+	return d.HandleGroup
+}
+
+// Services returns the cleanup enqueuer as the "auth-cleanup" service if it has to be run,
+// e.g. a queue.Processor, so it doesn't have to be registered separately.
+func (d *Domain) Services() []application.DomainService {
+	runner, ok := d.cleanupEnqueuer.(application.Runner)
+	if !ok {
+		return nil
+	}
+
+	return []application.DomainService{{Name: "auth-cleanup", Runner: runner}}
+}
+
 func New(db db, authStorage authStorage, sessionCookieName string, usernameValidator, passwordValidator func(string) error, cleanupEnqueuer cleanupEnqueuer) *Domain {
 	repository := NewRepository(db)
 	service := NewService(repository, authStorage, sessionCookieName, usernameValidator, passwordValidator, cleanupEnqueuer)
@@ -40,5 +61,27 @@ func New(db db, authStorage authStorage, sessionCookieName string, usernameValid
 		Service:     service,
 		HandleGroup: authAPI,
 		Middleware:  authMiddleware,
+
+		cleanupEnqueuer: cleanupEnqueuer,
+	}
+}
+
+// Provider returns a provider of the domain for application.ProvideDomain.
+// The database connection and the session storage are resolved from the container,
+// the cleanup enqueuer is used if the container provides one.
+func Provider(sessionCookieName string, usernameValidator, passwordValidator func(string) error) func(*application.Container) (*Domain, error) {
+	return func(c *application.Container) (*Domain, error) {
+		var deps struct {
+			DB              db              `inject:""`
+			AuthStorage     authStorage     `inject:""`
+			CleanupEnqueuer cleanupEnqueuer `inject:",optional"`
+		}
+
+		err := application.Inject(c, &deps)
+		if err != nil {
+			return nil, err
+		}
+
+		return New(deps.DB, deps.AuthStorage, sessionCookieName, usernameValidator, passwordValidator, deps.CleanupEnqueuer), nil
 	}
 }
@@ -8,20 +8,26 @@ import (
 	"github.com/platforma-dev/platforma/database"
 )
 
+// db is the database connection of the repository, e.g. *database.Database.
+// It has to run transactions, so that deleting a user and their sessions is atomic.
 type db interface {
 	NamedExecContext(ctx context.Context, query string, arg any) (sql.Result, error)
 	GetContext(ctx context.Context, dest any, query string, args ...any) error
 	SelectContext(ctx context.Context, dest any, query string, args ...any) error
 	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	WithTx(ctx context.Context, fn func(ctx context.Context) error, opts ...database.TxOption) error
 }
 
+// Repository stores users. Get, Create and Delete come from the embedded database.CRUDRepository.
 type Repository struct {
+	*database.CRUDRepository[User]
 	db db
 }
 
 func NewRepository(db db) *Repository {
 	return &Repository{
-		db: db,
+		CRUDRepository: database.NewCRUDRepository[User](db, "users"),
+		db:             db,
 	}
 }
 
@@ -42,13 +48,13 @@ func (r *Repository) Migrations() []database.Migration {
 
 }
 
-func (r *Repository) Get(ctx context.Context, id string) (*User, error) {
-	var user User
-	err := r.db.GetContext(ctx, &user, "SELECT * FROM users WHERE id = $1", id)
+// WithTx runs fn in a transaction. Queries of repositories sharing the database run in it.
+func (r *Repository) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := r.db.WithTx(ctx, fn)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user by id: %w", err)
+		return fmt.Errorf("transaction failed: %w", err)
 	}
-	return &user, nil
+	return nil
 }
 
 func (r *Repository) GetByUsername(ctx context.Context, username string) (*User, error) {
@@ -60,18 +66,6 @@ func (r *Repository) GetByUsername(ctx context.Context, username string) (*User,
 	return &user, nil
 }
 
-func (r *Repository) Create(ctx context.Context, user *User) error {
-	query := `
-		INSERT INTO users (id, username, password, salt, created, updated, status)
-		VALUES (:id, :username, :password, :salt, :created, :updated, :status)
-	`
-	_, err := r.db.NamedExecContext(ctx, query, user)
-	if err != nil {
-		return fmt.Errorf("failed to create user: %w", err)
-	}
-	return nil
-}
-
 func (r *Repository) UpdatePassword(ctx context.Context, id, password, salt string) error {
 	query := `
 		UPDATE users
@@ -84,12 +78,3 @@ func (r *Repository) UpdatePassword(ctx context.Context, id, password, salt stri
 	}
 	return nil
 }
-
-func (r *Repository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM users WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
-	if err != nil {
-		return fmt.Errorf("failed to delete user: %w", err)
-	}
-	return nil
-}
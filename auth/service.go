@@ -13,11 +13,12 @@ import (
 )
 
 type repository interface {
-	Get(ctx context.Context, id string) (*User, error)
+	Get(ctx context.Context, id any) (*User, error)
 	GetByUsername(ctx context.Context, username string) (*User, error)
 	Create(ctx context.Context, user *User) error
 	UpdatePassword(ctx context.Context, id, password, salt string) error
-	Delete(ctx context.Context, id string) error
+	Delete(ctx context.Context, id any) error
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 type authStorage interface {
@@ -180,14 +181,22 @@ func (s *Service) DeleteUser(ctx context.Context) error {
 		return ErrUserNotFound
 	}
 
-	err := s.authStorage.DeleteSessionsByUserId(ctx, user.ID)
-	if err != nil {
-		return fmt.Errorf("failed to delete user sessions: %w", err)
-	}
+	// Sessions and the user are deleted in one transaction, so a failure doesn't leave the user half deleted
+	err := s.repo.WithTx(ctx, func(ctx context.Context) error {
+		err := s.authStorage.DeleteSessionsByUserId(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("failed to delete user sessions: %w", err)
+		}
+
+		err = s.repo.Delete(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("failed to delete user: %w", err)
+		}
 
-	err = s.repo.Delete(ctx, user.ID)
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to delete user: %w", err)
+		return err
 	}
 
 	if s.cleanupEnqueuer != nil {
@@ -0,0 +1,152 @@
+// Package config provides loading of typed application configuration
+// from defaults, files, environment variables and command line flags.
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+)
+
+// ErrRequired is returned when a required configuration value is not set by any source.
+var ErrRequired = errors.New("required value is not set")
+
+// ErrInvalidValue is returned when a configuration value can't be converted to the field type.
+var ErrInvalidValue = errors.New("invalid value")
+
+// ErrUnsupportedType is returned when a configuration struct contains a field of unsupported type.
+var ErrUnsupportedType = errors.New("unsupported field type")
+
+// ErrUnknownFileFormat is returned when a configuration file extension is not one of .json, .yaml, .yml or .toml.
+var ErrUnknownFileFormat = errors.New("unknown config file format")
+
+// ErrNotStruct is returned when the configuration type is not a struct.
+var ErrNotStruct = errors.New("config type must be a struct")
+
+const redacted = "******"
+
+// Validator can be implemented by configuration structs to validate loaded values.
+type Validator interface {
+	// Validate returns an error if the configuration is not valid.
+	Validate() error
+}
+
+// Config loads configuration into a value of type T.
+//
+// Struct fields are configured with tags:
+//   - `config:"name"` sets the key of the field, by default the lowercased field name is used. Use "-" to skip the field.
+//   - `default:"value"` sets the default value.
+//   - `required:"true"` makes loading fail if the value is not set by any source.
+//   - `secret:"true"` hides the value in String output.
+//   - `env:"NAME"` overrides the environment variable name.
+//
+// Sources are applied in the following order, so later sources override earlier ones:
+// defaults, files, environment variables, flags.
+type Config[T any] struct {
+	sources sources
+	value   T
+	fields  []*field
+	once    sync.Once
+	err     error
+}
+
+// New creates a new Config that loads values from the given sources.
+func New[T any](opts ...Option) *Config[T] {
+	c := &Config[T]{sources: sources{lookupEnv: os.LookupEnv}}
+
+	for _, opt := range opts {
+		opt(&c.sources)
+	}
+
+	return c
+}
+
+// Load loads and validates the configuration. Configuration is loaded only once,
+// subsequent calls return the result of the first call.
+func (c *Config[T]) Load(_ context.Context) error {
+	c.once.Do(func() {
+		c.err = c.load()
+	})
+
+	return c.err
+}
+
+// SetFlags replaces the arguments given to FromFlags. The application calls it with the application flags
+// of the executed command, see application.Application.Execute, so that flags following the command name
+// are loaded too. Only flags named after fields of the config are loaded and others are ignored,
+// so several configs can share the application flags. It has no effect if the config has no FromFlags source
+// or is already loaded.
+func (c *Config[T]) SetFlags(args []string) {
+	if c.sources.flags {
+		c.sources.args = args
+		c.sources.sharedArgs = true
+	}
+}
+
+// Value returns the loaded configuration. It must be called after a successful Load.
+func (c *Config[T]) Value() *T {
+	return &c.value
+}
+
+// Values returns all configuration values by their keys. Values of secret fields are redacted.
+func (c *Config[T]) Values() map[string]string {
+	values := make(map[string]string, len(c.fields))
+
+	for _, f := range c.fields {
+		value := f.String()
+		if f.secret && value != "" {
+			value = redacted
+		}
+		values[f.key()] = value
+	}
+
+	return values
+}
+
+// String returns configuration values as JSON object. Values of secret fields are redacted.
+func (c *Config[T]) String() string {
+	b, _ := json.Marshal(c.Values())
+	return string(b)
+}
+
+func (c *Config[T]) load() error {
+	root := reflect.ValueOf(&c.value).Elem()
+	if root.Kind() != reflect.Struct {
+		return fmt.Errorf("%w: got %s", ErrNotStruct, root.Kind())
+	}
+
+	fields, err := collectFields(root, nil)
+	if err != nil {
+		return err
+	}
+	c.fields = fields
+
+	err = c.sources.apply(fields)
+	if err != nil {
+		return err
+	}
+
+	var validationErrs []error
+	for _, f := range fields {
+		if f.required && !f.isSet {
+			validationErrs = append(validationErrs, fmt.Errorf("%s: %w", f.key(), ErrRequired))
+		}
+	}
+
+	if validator, ok := any(&c.value).(Validator); ok && len(validationErrs) == 0 {
+		err := validator.Validate()
+		if err != nil {
+			validationErrs = append(validationErrs, err)
+		}
+	}
+
+	if len(validationErrs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(validationErrs...))
+	}
+
+	return nil
+}
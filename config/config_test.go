@@ -0,0 +1,264 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/platforma-dev/platforma/config"
+)
+
+type databaseConfig struct {
+	DSN      string `config:"dsn" required:"true"`
+	Password string `config:"password" secret:"true"`
+}
+
+type testConfig struct {
+	Name     string        `config:"name" default:"app"`
+	Port     int           `config:"port" default:"8080"`
+	Debug    bool          `config:"debug"`
+	Timeout  time.Duration `config:"timeout" default:"5s"`
+	Tags     []string      `config:"tags"`
+	Database databaseConfig
+	Ignored  string `config:"-"`
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	err := os.WriteFile(path, []byte(content), 0o600)
+	if err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	return path
+}
+
+func envLookup(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.New[testConfig](config.FromEnvLookup("app", envLookup(map[string]string{"APP_DATABASE_DSN": "postgres://localhost"})))
+
+	err := cfg.Load(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	value := cfg.Value()
+	if value.Name != "app" || value.Port != 8080 || value.Timeout != 5*time.Second {
+		t.Fatalf("unexpected defaults: %+v", value)
+	}
+
+	if value.Database.DSN != "postgres://localhost" {
+		t.Fatalf("expected dsn from env, got %q", value.Database.DSN)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{
+		"config.json": `{"port": 9000, "tags": ["a", "b"], "database": {"dsn": "json"}}`,
+		"config.yaml": "port: 9000\ntags: [a, b]\ndatabase:\n  dsn: yaml\n",
+		"config.toml": "port = 9000\ntags = [\"a\", \"b\"]\n[database]\ndsn = \"toml\"\n",
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := config.New[testConfig](config.FromFile(writeFile(t, name, content)))
+
+			err := cfg.Load(context.Background())
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			value := cfg.Value()
+			if value.Port != 9000 {
+				t.Fatalf("expected port 9000, got %d", value.Port)
+			}
+
+			if strings.Join(value.Tags, ",") != "a,b" {
+				t.Fatalf("expected tags [a b], got %v", value.Tags)
+			}
+
+			if value.Database.DSN != strings.TrimPrefix(filepath.Ext(name), ".") {
+				t.Fatalf("unexpected dsn %q", value.Database.DSN)
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := config.New[testConfig](config.FromFile(filepath.Join(t.TempDir(), "missing.json")))
+
+		err := cfg.Load(context.Background())
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("expected not exist error, got %v", err)
+		}
+	})
+
+	t.Run("missing optional file", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := config.New[testConfig](
+			config.FromOptionalFile(filepath.Join(t.TempDir(), "missing.json")),
+			config.FromFlags([]string{"-database.dsn=flag"}),
+		)
+
+		err := cfg.Load(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := config.New[testConfig](config.FromFile(writeFile(t, "config.ini", "port=1")))
+
+		err := cfg.Load(context.Background())
+		if !errors.Is(err, config.ErrUnknownFileFormat) {
+			t.Fatalf("expected ErrUnknownFileFormat, got %v", err)
+		}
+	})
+}
+
+func TestPrecedence(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.New[testConfig](
+		config.FromFile(writeFile(t, "config.json", `{"name": "file", "port": 1, "debug": true, "database": {"dsn": "file"}}`)),
+		config.FromEnvLookup("app", envLookup(map[string]string{"APP_PORT": "2", "APP_DATABASE_DSN": "env"})),
+		config.FromFlags([]string{"-database.dsn", "flag"}),
+	)
+
+	err := cfg.Load(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	value := cfg.Value()
+	if value.Name != "file" || !value.Debug {
+		t.Fatalf("expected values from file, got %+v", value)
+	}
+
+	if value.Port != 2 {
+		t.Fatalf("expected port from env, got %d", value.Port)
+	}
+
+	if value.Database.DSN != "flag" {
+		t.Fatalf("expected dsn from flags, got %q", value.Database.DSN)
+	}
+}
+
+func TestSetFlags(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.New[testConfig](config.FromFlags(nil))
+	cfg.SetFlags([]string{"-workers", "4", "-port=9090", "--queue=jobs", "-database.dsn", "flag"})
+
+	err := cfg.Load(context.Background())
+	if err != nil {
+		t.Fatalf("expected flags of other configs to be ignored, got %v", err)
+	}
+
+	if cfg.Value().Port != 9090 || cfg.Value().Database.DSN != "flag" {
+		t.Fatalf("expected own flags to be loaded, got %+v", cfg.Value())
+	}
+}
+
+func TestRequired(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.New[testConfig]()
+
+	err := cfg.Load(context.Background())
+	if !errors.Is(err, config.ErrRequired) {
+		t.Fatalf("expected ErrRequired, got %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "database.dsn") {
+		t.Fatalf("expected error to name the field, got %v", err)
+	}
+}
+
+func TestInvalidValue(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.New[testConfig](config.FromEnvLookup("", envLookup(map[string]string{"PORT": "not a number", "DATABASE_DSN": "dsn"})))
+
+	err := cfg.Load(context.Background())
+	if !errors.Is(err, config.ErrInvalidValue) {
+		t.Fatalf("expected ErrInvalidValue, got %v", err)
+	}
+}
+
+func TestSecretsAreRedacted(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.New[testConfig](config.FromFlags([]string{"-database.dsn=dsn", "-database.password=hunter2"}))
+
+	err := cfg.Load(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if cfg.Value().Database.Password != "hunter2" {
+		t.Fatalf("expected password to be loaded, got %q", cfg.Value().Database.Password)
+	}
+
+	if strings.Contains(cfg.String(), "hunter2") {
+		t.Fatalf("expected password to be redacted, got %s", cfg.String())
+	}
+
+	if cfg.Values()["database.password"] != "******" {
+		t.Fatalf("expected redacted password, got %q", cfg.Values()["database.password"])
+	}
+}
+
+var errPortTooLow = errors.New("port is too low")
+
+type validatedConfig struct {
+	Port int `config:"port" default:"80"`
+}
+
+func (c *validatedConfig) Validate() error {
+	if c.Port < 1024 {
+		return errPortTooLow
+	}
+
+	return nil
+}
+
+func TestValidator(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.New[validatedConfig]()
+
+	err := cfg.Load(context.Background())
+	if !errors.Is(err, errPortTooLow) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+
+	cfg = config.New[validatedConfig](config.FromFlags([]string{"-port=8080"}))
+
+	err = cfg.Load(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
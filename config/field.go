@@ -0,0 +1,232 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// field is a single configurable value of the configuration struct.
+type field struct {
+	path         []string
+	value        reflect.Value
+	env          string
+	defaultValue string
+	hasDefault   bool
+	required     bool
+	secret       bool
+	isSet        bool
+}
+
+// key returns the dotted key of the field, e.g. "database.dsn".
+func (f *field) key() string {
+	return strings.Join(f.path, ".")
+}
+
+// envName returns the environment variable name of the field for the given prefix.
+func (f *field) envName(prefix string) string {
+	if f.env != "" {
+		return f.env
+	}
+
+	name := strings.ToUpper(strings.Join(f.path, "_"))
+	if prefix != "" {
+		name = strings.ToUpper(prefix) + "_" + name
+	}
+
+	return name
+}
+
+// set converts the string to the field type and sets it.
+func (f *field) set(s string) error {
+	err := setValue(f.value, s)
+	if err != nil {
+		return fmt.Errorf("%s: %w", f.key(), err)
+	}
+
+	f.isSet = true
+	return nil
+}
+
+// setList sets slice field from a list of values.
+func (f *field) setList(items []string) error {
+	if f.value.Kind() != reflect.Slice {
+		return fmt.Errorf("%s: %w: list given for %s", f.key(), ErrInvalidValue, f.value.Type())
+	}
+
+	slice := reflect.MakeSlice(f.value.Type(), len(items), len(items))
+	for i, item := range items {
+		err := setValue(slice.Index(i), item)
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.key(), err)
+		}
+	}
+
+	f.value.Set(slice)
+	f.isSet = true
+
+	return nil
+}
+
+// String returns the current value of the field as string.
+func (f *field) String() string {
+	if marshaler, ok := f.value.Interface().(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		if err == nil {
+			return string(text)
+		}
+	}
+
+	if f.value.Kind() == reflect.Slice {
+		items := make([]string, f.value.Len())
+		for i := range f.value.Len() {
+			items[i] = fmt.Sprint(f.value.Index(i).Interface())
+		}
+		return strings.Join(items, ",")
+	}
+
+	return fmt.Sprint(f.value.Interface())
+}
+
+// collectFields walks the struct recursively and returns all its configurable fields.
+func collectFields(v reflect.Value, path []string) ([]*field, error) {
+	var fields []*field
+
+	t := v.Type()
+	for i := range t.NumField() {
+		structField := t.Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+
+		name := structField.Tag.Get("config")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(structField.Name)
+		}
+
+		fieldPath := append(append([]string{}, path...), name)
+		fieldValue := v.Field(i)
+
+		if isNestedStruct(fieldValue) {
+			nested, err := collectFields(fieldValue, fieldPath)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, nested...)
+			continue
+		}
+
+		if !isSupported(fieldValue.Type()) {
+			return nil, fmt.Errorf("%w: %s has type %s", ErrUnsupportedType, strings.Join(fieldPath, "."), fieldValue.Type())
+		}
+
+		f := &field{
+			path:     fieldPath,
+			value:    fieldValue,
+			env:      structField.Tag.Get("env"),
+			required: structField.Tag.Get("required") == "true",
+			secret:   structField.Tag.Get("secret") == "true",
+		}
+		f.defaultValue, f.hasDefault = structField.Tag.Lookup("default")
+
+		fields = append(fields, f)
+	}
+
+	return fields, nil
+}
+
+func isNestedStruct(v reflect.Value) bool {
+	return v.Kind() == reflect.Struct && !reflect.PointerTo(v.Type()).Implements(reflect.TypeFor[encoding.TextUnmarshaler]())
+}
+
+func isSupported(t reflect.Type) bool {
+	if reflect.PointerTo(t).Implements(reflect.TypeFor[encoding.TextUnmarshaler]()) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() != reflect.Slice && isSupported(t.Elem())
+	default:
+		return false
+	}
+}
+
+// setValue converts the string to the type of v and sets it.
+// Slices are parsed from comma separated values.
+func setValue(v reflect.Value, s string) error {
+	if unmarshaler, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		err := unmarshaler.UnmarshalText([]byte(s))
+		if err != nil {
+			return fmt.Errorf("%w %q: %w", ErrInvalidValue, s, err)
+		}
+		return nil
+	}
+
+	if v.Type() == reflect.TypeFor[time.Duration]() {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("%w %q: %w", ErrInvalidValue, s, err)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("%w %q: %w", ErrInvalidValue, s, err)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%w %q: %w", ErrInvalidValue, s, err)
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%w %q: %w", ErrInvalidValue, s, err)
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		fl, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%w %q: %w", ErrInvalidValue, s, err)
+		}
+		v.SetFloat(fl)
+	case reflect.Slice:
+		items := []string{}
+		if s != "" {
+			items = strings.Split(s, ",")
+		}
+
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			err := setValue(slice.Index(i), strings.TrimSpace(item))
+			if err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedType, v.Type())
+	}
+
+	return nil
+}
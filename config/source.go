@@ -0,0 +1,254 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Option configures sources of a Config.
+type Option func(*sources)
+
+// FromFile loads values from the JSON, YAML or TOML file. The format is chosen by the file extension.
+// Loading fails if the file does not exist.
+func FromFile(path string) Option {
+	return func(s *sources) {
+		s.files = append(s.files, configFile{path: path})
+	}
+}
+
+// FromOptionalFile loads values from the JSON, YAML or TOML file if it exists.
+func FromOptionalFile(path string) Option {
+	return func(s *sources) {
+		s.files = append(s.files, configFile{path: path, optional: true})
+	}
+}
+
+// FromEnv loads values from environment variables. Variable names are built from
+// the uppercased field keys joined with underscore and prefixed with prefix,
+// e.g. APP_DATABASE_DSN for prefix "app" and key "database.dsn".
+func FromEnv(prefix string) Option {
+	return FromEnvLookup(prefix, os.LookupEnv)
+}
+
+// FromEnvLookup is like FromEnv but looks variables up with the given function.
+func FromEnvLookup(prefix string, lookup func(string) (string, bool)) Option {
+	return func(s *sources) {
+		s.env = true
+		s.envPrefix = prefix
+		s.lookupEnv = lookup
+	}
+}
+
+// FromFlags loads values from command line arguments. Every field is available as a flag
+// named after its key, e.g. -database.dsn=postgres://localhost.
+func FromFlags(args []string) Option {
+	return func(s *sources) {
+		s.flags = true
+		s.args = args
+	}
+}
+
+type configFile struct {
+	path     string
+	optional bool
+}
+
+type sources struct {
+	files      []configFile
+	env        bool
+	envPrefix  string
+	lookupEnv  func(string) (string, bool)
+	flags      bool
+	args       []string
+	sharedArgs bool // args may contain flags of other configs
+}
+
+// apply sets field values from all sources in order of their precedence.
+func (s *sources) apply(fields []*field) error {
+	for _, f := range fields {
+		if f.hasDefault {
+			err := f.set(f.defaultValue)
+			if err != nil {
+				return fmt.Errorf("invalid default value: %w", err)
+			}
+		}
+	}
+
+	for _, file := range s.files {
+		err := applyFile(file, fields)
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.env {
+		for _, f := range fields {
+			if value, ok := s.lookupEnv(f.envName(s.envPrefix)); ok {
+				err := f.set(value)
+				if err != nil {
+					return fmt.Errorf("invalid environment variable %s: %w", f.envName(s.envPrefix), err)
+				}
+			}
+		}
+	}
+
+	if s.flags {
+		args := s.args
+		if s.sharedArgs {
+			args = ownFlags(args, fields)
+		}
+
+		err := applyFlags(args, fields)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func applyFile(file configFile, fields []*field) error {
+	content, err := os.ReadFile(file.path)
+	if err != nil {
+		if file.optional && errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	data := map[string]any{}
+
+	switch strings.ToLower(filepath.Ext(file.path)) {
+	case ".json":
+		err = json.Unmarshal(content, &data)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &data)
+	case ".toml":
+		err = toml.Unmarshal(content, &data)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownFileFormat, file.path)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", file.path, err)
+	}
+
+	values := map[string]any{}
+	flatten(data, "", values)
+
+	for _, f := range fields {
+		value, ok := values[f.key()]
+		if !ok {
+			continue
+		}
+
+		if list, ok := value.([]any); ok {
+			items := make([]string, len(list))
+			for i, item := range list {
+				items[i] = scalarString(item)
+			}
+			err = f.setList(items)
+		} else {
+			err = f.set(scalarString(value))
+		}
+
+		if err != nil {
+			return fmt.Errorf("invalid value in config file %s: %w", file.path, err)
+		}
+	}
+
+	return nil
+}
+
+// flatten converts nested maps to a flat map with dotted keys.
+func flatten(data map[string]any, prefix string, values map[string]any) {
+	for key, value := range data {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		if nested, ok := value.(map[string]any); ok {
+			flatten(nested, key, values)
+			continue
+		}
+
+		values[key] = value
+	}
+}
+
+// scalarString formats a decoded file value so it can be parsed back to the field type.
+func scalarString(value any) string {
+	if f, ok := value.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+
+	return fmt.Sprint(value)
+}
+
+// ownFlags returns flags of args named after keys of the fields, with their values.
+// Values of other flags given as separate arguments are skipped too, as all config flags take a value.
+func ownFlags(args []string, fields []*field) []string {
+	keys := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		keys[f.key()] = true
+	}
+
+	own := []string{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" || len(arg) < 2 || arg[0] != '-' {
+			break
+		}
+
+		name, _, hasValue := strings.Cut(strings.TrimPrefix(arg[1:], "-"), "=")
+		takesNext := !hasValue && i+1 < len(args)
+		if keys[name] {
+			own = append(own, arg)
+			if takesNext {
+				own = append(own, args[i+1])
+			}
+		}
+
+		if takesNext {
+			i++
+		}
+	}
+	return own
+}
+
+func applyFlags(args []string, fields []*field) error {
+	flagSet := flag.NewFlagSet("config", flag.ContinueOnError)
+	flagSet.SetOutput(io.Discard)
+
+	byKey := make(map[string]*field, len(fields))
+	for _, f := range fields {
+		byKey[f.key()] = f
+		flagSet.String(f.key(), f.defaultValue, "")
+	}
+
+	err := flagSet.Parse(args)
+	if err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	var setErr error
+	flagSet.Visit(func(fl *flag.Flag) {
+		err := byKey[fl.Name].set(fl.Value.String())
+		if err != nil {
+			setErr = errors.Join(setErr, fmt.Errorf("invalid flag -%s: %w", fl.Name, err))
+		}
+	})
+
+	return setErr
+}
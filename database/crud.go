@@ -0,0 +1,503 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrUnknownColumn is returned when a filter or a sort of CRUDRepository.List names a column
+// that is not mapped by a db struct tag.
+var ErrUnknownColumn = errors.New("unknown column")
+
+// ErrInvalidCursor is returned when ListOptions.After doesn't hold a value for every sort column.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ErrInvalidFilter is returned when a filter of CRUDRepository.List has an unknown operator
+// or a value the operator can't use.
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// ErrNoColumnsToUpdate is returned by CRUDRepository.Update when the row has no columns
+// besides the primary key and the soft delete column.
+var ErrNoColumnsToUpdate = errors.New("no columns to update")
+
+// FilterOp is a comparison operator of a Filter.
+type FilterOp string
+
+// Operators of filters.
+const (
+	FilterEq      FilterOp = "="
+	FilterNotEq   FilterOp = "<>"
+	FilterLt      FilterOp = "<"
+	FilterLte     FilterOp = "<="
+	FilterGt      FilterOp = ">"
+	FilterGte     FilterOp = ">="
+	FilterLike    FilterOp = "LIKE"
+	FilterIn      FilterOp = "IN"          // Value is a slice
+	FilterIsNull  FilterOp = "IS NULL"     // Value is ignored
+	FilterNotNull FilterOp = "IS NOT NULL" // Value is ignored
+)
+
+// Filter compares a column with a value.
+type Filter struct {
+	Column string
+	Op     FilterOp
+	Value  any
+}
+
+// Sort orders rows by a column.
+type Sort struct {
+	Column string
+	Desc   bool
+}
+
+// ListOptions configures CRUDRepository.List.
+type ListOptions struct {
+	Filters        []Filter // Combined with AND
+	Sort           []Sort   // The primary key is added as the last sort column, so the order is stable
+	Limit          int      // Maximum number of rows, all rows by default
+	After          []any    // Cursor of the previous page, see Page.Next
+	IncludeDeleted bool     // Include soft deleted rows
+}
+
+// Page is a page of rows returned by CRUDRepository.List.
+type Page[T any] struct {
+	Items []T
+	// Next is the cursor of the next page to pass to ListOptions.After, nil on the last page.
+	// It holds values of sort columns of the last row, so the pages stay consistent while rows are added.
+	Next []any
+}
+
+// CRUDOption configures a CRUDRepository.
+type CRUDOption func(*crudConfig)
+
+type crudConfig struct {
+	key        string
+	softDelete string
+}
+
+// WithPrimaryKey sets the primary key column, "id" by default.
+func WithPrimaryKey(column string) CRUDOption {
+	return func(c *crudConfig) {
+		c.key = column
+	}
+}
+
+// WithSoftDelete makes Delete set the timestamp column instead of deleting rows. Rows with the column set
+// are skipped by Get, List and Update. The field of the column should be a *time.Time or sql.NullTime.
+func WithSoftDelete(column string) CRUDOption {
+	return func(c *crudConfig) {
+		c.softDelete = column
+	}
+}
+
+// crudDB is the part of Database used by CRUDRepository.
+type crudDB interface {
+	NamedExecContext(ctx context.Context, query string, arg any) (sql.Result, error)
+	GetContext(ctx context.Context, dest any, query string, args ...any) error
+	SelectContext(ctx context.Context, dest any, query string, args ...any) error
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// crudColumn is a column mapped to a field of the row type.
+type crudColumn struct {
+	name  string
+	index []int
+}
+
+// CRUDRepository implements common queries of a table whose rows map to T with db struct tags.
+// Domain repositories embed it and add custom queries and migrations:
+//
+//	type Repository struct {
+//		*database.CRUDRepository[User]
+//	}
+//
+//	func NewRepository(db *database.Database) *Repository {
+//		return &Repository{CRUDRepository: database.NewCRUDRepository[User](db, "users")}
+//	}
+//
+// Queries use $1, $2, ... placeholders and identifiers quoted by the dialect of the database,
+// so db should be a Database for dialects other than PostgreSQL.
+// Queries run in the transaction of the context, see Database.WithTx.
+type CRUDRepository[T any] struct {
+	db         crudDB
+	dialect    Dialect
+	table      string
+	columns    []crudColumn
+	key        string
+	softDelete string
+}
+
+// NewCRUDRepository creates a repository of the table. Columns are fields of T with db struct tags,
+// including fields of embedded structs. It panics if T has no such fields or lacks the primary key
+// or the soft delete column.
+func NewCRUDRepository[T any](db crudDB, table string, opts ...CRUDOption) *CRUDRepository[T] {
+	config := crudConfig{key: "id"}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	rowType := reflect.TypeFor[T]()
+	columns := crudColumns(rowType, nil)
+	if len(columns) == 0 {
+		panic(fmt.Sprintf("database: %s has no fields with db struct tags", rowType))
+	}
+
+	var dialect Dialect = Postgres{}
+	if d, ok := db.(interface{ Dialect() Dialect }); ok {
+		dialect = d.Dialect()
+	}
+
+	repo := &CRUDRepository[T]{
+		db:         db,
+		dialect:    dialect,
+		table:      table,
+		columns:    columns,
+		key:        config.key,
+		softDelete: config.softDelete,
+	}
+	for _, column := range []string{config.key, config.softDelete} {
+		if column != "" && !repo.hasColumn(column) {
+			panic(fmt.Sprintf("database: %s has no field of %s column", rowType, column))
+		}
+	}
+
+	return repo
+}
+
+// crudColumns returns columns of fields with db struct tags, recursing into untagged embedded structs.
+func crudColumns(t reflect.Type, index []int) []crudColumn {
+	columns := []crudColumn{}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		fieldIndex := append(slices.Clone(index), i)
+
+		tag, ok := field.Tag.Lookup("db")
+		name, _, _ := strings.Cut(tag, ",")
+		switch {
+		case name == "-":
+		case !ok && field.Anonymous && field.Type.Kind() == reflect.Struct:
+			columns = append(columns, crudColumns(field.Type, fieldIndex)...)
+		case ok && name != "" && field.IsExported():
+			columns = append(columns, crudColumn{name: name, index: fieldIndex})
+		}
+	}
+	return columns
+}
+
+// Get returns the row with the primary key. The error wraps sql.ErrNoRows if there is none.
+func (r *CRUDRepository[T]) Get(ctx context.Context, id any) (*T, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s = $1", r.columnList(), r.quote(r.table), r.quote(r.key))
+	if r.softDelete != "" {
+		query += fmt.Sprintf(" AND %s IS NULL", r.quote(r.softDelete))
+	}
+
+	var row T
+	err := r.db.GetContext(ctx, &row, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s row: %w", r.table, err)
+	}
+	return &row, nil
+}
+
+// List returns rows matching the filters in the sort order, a page at a time if Limit is set.
+func (r *CRUDRepository[T]) List(ctx context.Context, opts ListOptions) (Page[T], error) {
+	sorts := slices.Clone(opts.Sort)
+	if !slices.ContainsFunc(sorts, func(s Sort) bool { return s.Column == r.key }) {
+		sorts = append(sorts, Sort{Column: r.key})
+	}
+
+	for _, s := range sorts {
+		if !r.hasColumn(s.Column) {
+			return Page[T]{}, fmt.Errorf("%w: %s", ErrUnknownColumn, s.Column)
+		}
+	}
+
+	conditions := []string{}
+	args := []any{}
+	placeholder := func(arg any) string {
+		args = append(args, arg)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if r.softDelete != "" && !opts.IncludeDeleted {
+		conditions = append(conditions, r.quote(r.softDelete)+" IS NULL")
+	}
+
+	for _, filter := range opts.Filters {
+		condition, err := r.filterCondition(filter, placeholder)
+		if err != nil {
+			return Page[T]{}, err
+		}
+		conditions = append(conditions, condition)
+	}
+
+	if opts.After != nil {
+		if len(opts.After) != len(sorts) {
+			return Page[T]{}, fmt.Errorf("%w: %d values for %d sort columns", ErrInvalidCursor, len(opts.After), len(sorts))
+		}
+		conditions = append(conditions, r.keysetCondition(sorts, opts.After, placeholder))
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s", r.columnList(), r.quote(r.table))
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	orderBy := make([]string, len(sorts))
+	for i, s := range sorts {
+		orderBy[i] = r.quote(s.Column) + sortDirection(s.Desc)
+	}
+	query += " ORDER BY " + strings.Join(orderBy, ", ")
+
+	if opts.Limit > 0 {
+		// One more row tells whether there is a next page
+		query += " LIMIT " + strconv.Itoa(opts.Limit+1)
+	}
+
+	items := []T{}
+	err := r.db.SelectContext(ctx, &items, query, args...)
+	if err != nil {
+		return Page[T]{}, fmt.Errorf("failed to list %s rows: %w", r.table, err)
+	}
+
+	page := Page[T]{Items: items}
+	if opts.Limit > 0 && len(items) > opts.Limit {
+		page.Items = items[:opts.Limit]
+		page.Next = r.cursor(page.Items[opts.Limit-1], sorts)
+	}
+
+	return page, nil
+}
+
+// Create inserts the row.
+func (r *CRUDRepository[T]) Create(ctx context.Context, row *T) error {
+	_, err := r.db.NamedExecContext(ctx, r.insertQuery(), row)
+	if err != nil {
+		return fmt.Errorf("failed to create %s row: %w", r.table, err)
+	}
+	return nil
+}
+
+// Update updates all columns of the row with its primary key, except the soft delete column.
+// The error wraps sql.ErrNoRows if there is no such row.
+func (r *CRUDRepository[T]) Update(ctx context.Context, row *T) error {
+	assignments := []string{}
+	for _, column := range r.updatedColumns() {
+		assignments = append(assignments, r.quote(column)+" = :"+column)
+	}
+
+	if len(assignments) == 0 {
+		return fmt.Errorf("failed to update %s row: %w", r.table, ErrNoColumnsToUpdate)
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s = :%s",
+		r.quote(r.table), strings.Join(assignments, ", "), r.quote(r.key), r.key)
+	if r.softDelete != "" {
+		query += fmt.Sprintf(" AND %s IS NULL", r.quote(r.softDelete))
+	}
+
+	result, err := r.db.NamedExecContext(ctx, query, row)
+	if err != nil {
+		return fmt.Errorf("failed to update %s row: %w", r.table, err)
+	}
+	return r.checkAffected(result, "update")
+}
+
+// Upsert inserts the row or updates the row with the same primary key, except its soft delete column.
+func (r *CRUDRepository[T]) Upsert(ctx context.Context, row *T) error {
+	mysql := r.dialect.Name() == DialectMySQL
+
+	assignments := []string{}
+	for _, column := range r.updatedColumns() {
+		if mysql {
+			assignments = append(assignments, fmt.Sprintf("%s = VALUES(%s)", r.quote(column), r.quote(column)))
+		} else {
+			assignments = append(assignments, fmt.Sprintf("%s = excluded.%s", r.quote(column), r.quote(column)))
+		}
+	}
+
+	query := r.insertQuery()
+	switch {
+	case mysql && len(assignments) == 0:
+		query += fmt.Sprintf(" ON DUPLICATE KEY UPDATE %s = %s", r.quote(r.key), r.quote(r.key))
+	case mysql:
+		query += " ON DUPLICATE KEY UPDATE " + strings.Join(assignments, ", ")
+	case len(assignments) == 0:
+		query += fmt.Sprintf(" ON CONFLICT (%s) DO NOTHING", r.quote(r.key))
+	default:
+		query += fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s", r.quote(r.key), strings.Join(assignments, ", "))
+	}
+
+	_, err := r.db.NamedExecContext(ctx, query, row)
+	if err != nil {
+		return fmt.Errorf("failed to upsert %s row: %w", r.table, err)
+	}
+	return nil
+}
+
+// Delete deletes the row with the primary key, or marks it as deleted if the repository uses soft delete.
+// The error wraps sql.ErrNoRows if there is no such row.
+func (r *CRUDRepository[T]) Delete(ctx context.Context, id any) error {
+	if r.softDelete == "" {
+		return r.HardDelete(ctx, id)
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET %s = $1 WHERE %s = $2 AND %s IS NULL",
+		r.quote(r.table), r.quote(r.softDelete), r.quote(r.key), r.quote(r.softDelete))
+	result, err := r.db.ExecContext(ctx, query, time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to delete %s row: %w", r.table, err)
+	}
+	return r.checkAffected(result, "delete")
+}
+
+// Restore clears the soft delete column of the row with the primary key.
+// The error wraps sql.ErrNoRows if there is no such deleted row.
+func (r *CRUDRepository[T]) Restore(ctx context.Context, id any) error {
+	if r.softDelete == "" {
+		return nil
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET %s = NULL WHERE %s = $1 AND %s IS NOT NULL",
+		r.quote(r.table), r.quote(r.softDelete), r.quote(r.key), r.quote(r.softDelete))
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to restore %s row: %w", r.table, err)
+	}
+	return r.checkAffected(result, "restore")
+}
+
+// HardDelete deletes the row with the primary key even if the repository uses soft delete.
+// The error wraps sql.ErrNoRows if there is no such row.
+func (r *CRUDRepository[T]) HardDelete(ctx context.Context, id any) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s = $1", r.quote(r.table), r.quote(r.key))
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete %s row: %w", r.table, err)
+	}
+	return r.checkAffected(result, "delete")
+}
+
+// checkAffected returns an error wrapping sql.ErrNoRows if the query of the action matched no rows.
+func (r *CRUDRepository[T]) checkAffected(result sql.Result, action string) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to %s %s row: %w", action, r.table, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("failed to %s %s row: %w", action, r.table, sql.ErrNoRows)
+	}
+	return nil
+}
+
+func (r *CRUDRepository[T]) hasColumn(name string) bool {
+	return slices.ContainsFunc(r.columns, func(c crudColumn) bool { return c.name == name })
+}
+
+func (r *CRUDRepository[T]) columnList() string {
+	names := make([]string, len(r.columns))
+	for i, column := range r.columns {
+		names[i] = r.quote(column.name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func (r *CRUDRepository[T]) insertQuery() string {
+	params := make([]string, len(r.columns))
+	for i, column := range r.columns {
+		params[i] = ":" + column.name
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", r.quote(r.table), r.columnList(), strings.Join(params, ", "))
+}
+
+// updatedColumns returns columns set by Update and Upsert.
+func (r *CRUDRepository[T]) updatedColumns() []string {
+	columns := []string{}
+	for _, column := range r.columns {
+		if column.name != r.key && column.name != r.softDelete {
+			columns = append(columns, column.name)
+		}
+	}
+	return columns
+}
+
+func (r *CRUDRepository[T]) filterCondition(filter Filter, placeholder func(any) string) (string, error) {
+	if !r.hasColumn(filter.Column) {
+		return "", fmt.Errorf("%w: %s", ErrUnknownColumn, filter.Column)
+	}
+
+	column := r.quote(filter.Column)
+	switch filter.Op {
+	case FilterEq, FilterNotEq, FilterLt, FilterLte, FilterGt, FilterGte, FilterLike:
+		return fmt.Sprintf("%s %s %s", column, filter.Op, placeholder(filter.Value)), nil
+	case FilterIsNull, FilterNotNull:
+		return fmt.Sprintf("%s %s", column, filter.Op), nil
+	case FilterIn:
+		values := reflect.ValueOf(filter.Value)
+		if values.Kind() != reflect.Slice {
+			return "", fmt.Errorf("%w: IN of %s column needs a slice, got %T", ErrInvalidFilter, filter.Column, filter.Value)
+		}
+		if values.Len() == 0 {
+			return "1 = 0", nil
+		}
+
+		placeholders := make([]string, values.Len())
+		for i := range values.Len() {
+			placeholders[i] = placeholder(values.Index(i).Interface())
+		}
+		return fmt.Sprintf("%s IN (%s)", column, strings.Join(placeholders, ", ")), nil
+	default:
+		return "", fmt.Errorf("%w: unknown operator %q of %s column", ErrInvalidFilter, filter.Op, filter.Column)
+	}
+}
+
+// cursor returns values of the sort columns of the row.
+func (r *CRUDRepository[T]) cursor(row T, sorts []Sort) []any {
+	value := reflect.ValueOf(row)
+	cursor := make([]any, len(sorts))
+	for i, s := range sorts {
+		j := slices.IndexFunc(r.columns, func(c crudColumn) bool { return c.name == s.Column })
+		cursor[i] = value.FieldByIndex(r.columns[j].index).Interface()
+	}
+	return cursor
+}
+
+// keysetCondition selects rows after the cursor in the sort order:
+// (a > $1) OR (a = $1 AND b > $2) OR ...
+func (r *CRUDRepository[T]) keysetCondition(sorts []Sort, cursor []any, placeholder func(any) string) string {
+	alternatives := make([]string, len(sorts))
+	for i, s := range sorts {
+		parts := []string{}
+		for j := range i {
+			parts = append(parts, fmt.Sprintf("%s = %s", r.quote(sorts[j].Column), placeholder(cursor[j])))
+		}
+
+		op := ">"
+		if s.Desc {
+			op = "<"
+		}
+		parts = append(parts, fmt.Sprintf("%s %s %s", r.quote(s.Column), op, placeholder(cursor[i])))
+
+		alternatives[i] = "(" + strings.Join(parts, " AND ") + ")"
+	}
+	return "(" + strings.Join(alternatives, " OR ") + ")"
+}
+
+func sortDirection(desc bool) string {
+	if desc {
+		return " DESC"
+	}
+	return " ASC"
+}
+
+// quote quotes the identifier for the dialect of the database.
+func (r *CRUDRepository[T]) quote(name string) string {
+	return r.dialect.QuoteIdentifier(name)
+}
@@ -0,0 +1,323 @@
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/platforma-dev/platforma/database"
+)
+
+type timestamps struct {
+	Created time.Time  `db:"created"`
+	Deleted *time.Time `db:"deleted"`
+}
+
+type item struct {
+	ID    string `db:"id"`
+	Name  string `db:"name"`
+	Owner string `db:"user"`
+	Price int    `db:"price"`
+	Note  string `db:"-"`
+	timestamps
+}
+
+func TestCRUDRepository(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	newRepo := func(t *testing.T, opts ...database.CRUDOption) *database.CRUDRepository[item] {
+		t.Helper()
+
+		db, err := database.New(filepath.Join(t.TempDir(), "test.db"), database.WithDialect(database.SQLite{}))
+		if err != nil {
+			t.Fatalf("failed to initialize database: %s", err.Error())
+		}
+		t.Cleanup(func() { _ = db.Close() })
+
+		_, err = db.ExecContext(ctx, `CREATE TABLE items (
+			id TEXT PRIMARY KEY, name TEXT, "user" TEXT, price INTEGER, created TIMESTAMP, deleted TIMESTAMP
+		)`)
+		if err != nil {
+			t.Fatalf("failed to create table: %s", err.Error())
+		}
+
+		return database.NewCRUDRepository[item](db, "items", opts...)
+	}
+
+	create := func(t *testing.T, repo *database.CRUDRepository[item], items ...item) {
+		t.Helper()
+
+		for _, it := range items {
+			err := repo.Create(ctx, &it)
+			if err != nil {
+				t.Fatalf("failed to create item: %s", err.Error())
+			}
+		}
+	}
+
+	ids := func(items []item) []string {
+		result := []string{}
+		for _, it := range items {
+			result = append(result, it.ID)
+		}
+		return result
+	}
+
+	t.Run("create, get, update and delete", func(t *testing.T) {
+		t.Parallel()
+
+		repo := newRepo(t)
+		create(t, repo, item{ID: "1", Name: "apple", Owner: "alice", Price: 10, Note: "ignored", timestamps: timestamps{Created: time.Now().UTC()}})
+
+		got, err := repo.Get(ctx, "1")
+		if err != nil {
+			t.Fatalf("failed to get item: %s", err.Error())
+		}
+
+		if got.Name != "apple" || got.Owner != "alice" || got.Price != 10 || got.Created.IsZero() || got.Note != "" {
+			t.Fatalf("unexpected item: %+v", got)
+		}
+
+		got.Price = 12
+		err = repo.Update(ctx, got)
+		if err != nil {
+			t.Fatalf("failed to update item: %s", err.Error())
+		}
+
+		got, err = repo.Get(ctx, "1")
+		if err != nil || got.Price != 12 {
+			t.Fatalf("expected updated price, got %+v and error: %v", got, err)
+		}
+
+		err = repo.Delete(ctx, "1")
+		if err != nil {
+			t.Fatalf("failed to delete item: %s", err.Error())
+		}
+
+		_, err = repo.Get(ctx, "1")
+		if !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected sql.ErrNoRows, got: %v", err)
+		}
+	})
+
+	t.Run("missing rows", func(t *testing.T) {
+		t.Parallel()
+
+		repo := newRepo(t, database.WithSoftDelete("deleted"))
+		create(t, repo, item{ID: "1"})
+
+		err := repo.Update(ctx, &item{ID: "2", Price: 10})
+		if !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected update of missing item to return sql.ErrNoRows, got: %v", err)
+		}
+
+		err = repo.Restore(ctx, "1")
+		if !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected restore of not deleted item to return sql.ErrNoRows, got: %v", err)
+		}
+
+		err = repo.Delete(ctx, "1")
+		if err != nil {
+			t.Fatalf("failed to delete item: %s", err.Error())
+		}
+
+		err = repo.Delete(ctx, "1")
+		if !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected delete of deleted item to return sql.ErrNoRows, got: %v", err)
+		}
+
+		err = repo.HardDelete(ctx, "2")
+		if !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected delete of missing item to return sql.ErrNoRows, got: %v", err)
+		}
+	})
+
+	t.Run("update without columns", func(t *testing.T) {
+		t.Parallel()
+
+		type key struct {
+			ID string `db:"id"`
+		}
+
+		db, err := database.New(filepath.Join(t.TempDir(), "test.db"), database.WithDialect(database.SQLite{}))
+		if err != nil {
+			t.Fatalf("failed to initialize database: %s", err.Error())
+		}
+		t.Cleanup(func() { _ = db.Close() })
+
+		err = database.NewCRUDRepository[key](db, "keys").Update(ctx, &key{ID: "1"})
+		if !errors.Is(err, database.ErrNoColumnsToUpdate) {
+			t.Fatalf("expected ErrNoColumnsToUpdate, got: %v", err)
+		}
+	})
+
+	t.Run("upsert", func(t *testing.T) {
+		t.Parallel()
+
+		repo := newRepo(t)
+
+		for _, price := range []int{10, 20} {
+			err := repo.Upsert(ctx, &item{ID: "1", Name: "apple", Price: price})
+			if err != nil {
+				t.Fatalf("failed to upsert item: %s", err.Error())
+			}
+		}
+
+		page, err := repo.List(ctx, database.ListOptions{})
+		if err != nil {
+			t.Fatalf("failed to list items: %s", err.Error())
+		}
+
+		if len(page.Items) != 1 || page.Items[0].Price != 20 {
+			t.Fatalf("expected single updated item, got: %+v", page.Items)
+		}
+	})
+
+	t.Run("list with filters and sort", func(t *testing.T) {
+		t.Parallel()
+
+		repo := newRepo(t)
+		create(t, repo,
+			item{ID: "1", Name: "apple", Owner: "alice", Price: 10},
+			item{ID: "2", Name: "banana", Owner: "bob", Price: 5},
+			item{ID: "3", Name: "apricot", Owner: "alice", Price: 30},
+			item{ID: "4", Name: "cherry", Owner: "carol", Price: 20},
+		)
+
+		page, err := repo.List(ctx, database.ListOptions{
+			Filters: []database.Filter{
+				{Column: "user", Op: database.FilterIn, Value: []string{"alice", "carol"}},
+				{Column: "price", Op: database.FilterGte, Value: 10},
+			},
+			Sort: []database.Sort{{Column: "price", Desc: true}},
+		})
+		if err != nil {
+			t.Fatalf("failed to list items: %s", err.Error())
+		}
+
+		if !slices.Equal(ids(page.Items), []string{"3", "4", "1"}) || page.Next != nil {
+			t.Fatalf("expected items 3, 4, 1 on a single page, got: %v, next: %v", ids(page.Items), page.Next)
+		}
+
+		page, err = repo.List(ctx, database.ListOptions{
+			Filters: []database.Filter{{Column: "name", Op: database.FilterLike, Value: "ap%"}},
+		})
+		if err != nil {
+			t.Fatalf("failed to list items: %s", err.Error())
+		}
+
+		if !slices.Equal(ids(page.Items), []string{"1", "3"}) {
+			t.Fatalf("expected items 1 and 3, got: %v", ids(page.Items))
+		}
+
+		_, err = repo.List(ctx, database.ListOptions{Sort: []database.Sort{{Column: "price; DROP TABLE items"}}})
+		if !errors.Is(err, database.ErrUnknownColumn) {
+			t.Fatalf("expected ErrUnknownColumn, got: %v", err)
+		}
+
+		_, err = repo.List(ctx, database.ListOptions{Filters: []database.Filter{{Column: "price", Op: "~"}}})
+		if !errors.Is(err, database.ErrInvalidFilter) {
+			t.Fatalf("expected ErrInvalidFilter for unknown operator, got: %v", err)
+		}
+
+		_, err = repo.List(ctx, database.ListOptions{Filters: []database.Filter{{Column: "price", Op: database.FilterIn, Value: 10}}})
+		if !errors.Is(err, database.ErrInvalidFilter) {
+			t.Fatalf("expected ErrInvalidFilter for IN without slice, got: %v", err)
+		}
+
+		_, err = repo.List(ctx, database.ListOptions{After: []any{"1", "2"}})
+		if !errors.Is(err, database.ErrInvalidCursor) {
+			t.Fatalf("expected ErrInvalidCursor, got: %v", err)
+		}
+	})
+
+	t.Run("keyset pagination", func(t *testing.T) {
+		t.Parallel()
+
+		repo := newRepo(t)
+		create(t, repo,
+			item{ID: "1", Owner: "alice", Price: 10},
+			item{ID: "2", Owner: "bob", Price: 20},
+			item{ID: "3", Owner: "alice", Price: 20},
+			item{ID: "4", Owner: "carol", Price: 30},
+			item{ID: "5", Owner: "bob", Price: 10},
+		)
+
+		opts := database.ListOptions{Sort: []database.Sort{{Column: "price", Desc: true}}, Limit: 2}
+		pages := [][]string{}
+		for {
+			page, err := repo.List(ctx, opts)
+			if err != nil {
+				t.Fatalf("failed to list items: %s", err.Error())
+			}
+
+			pages = append(pages, ids(page.Items))
+			if page.Next == nil {
+				break
+			}
+			opts.After = page.Next
+		}
+
+		// Items with equal prices are ordered by ID
+		expected := [][]string{{"4", "2"}, {"3", "1"}, {"5"}}
+		if !slices.EqualFunc(pages, expected, slices.Equal) {
+			t.Fatalf("expected pages %v, got: %v", expected, pages)
+		}
+	})
+
+	t.Run("soft delete", func(t *testing.T) {
+		t.Parallel()
+
+		repo := newRepo(t, database.WithSoftDelete("deleted"))
+		create(t, repo, item{ID: "1"}, item{ID: "2"})
+
+		err := repo.Delete(ctx, "1")
+		if err != nil {
+			t.Fatalf("failed to delete item: %s", err.Error())
+		}
+
+		_, err = repo.Get(ctx, "1")
+		if !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected soft deleted item to be skipped, got: %v", err)
+		}
+
+		page, err := repo.List(ctx, database.ListOptions{IncludeDeleted: true})
+		if err != nil {
+			t.Fatalf("failed to list items: %s", err.Error())
+		}
+
+		if !slices.Equal(ids(page.Items), []string{"1", "2"}) || page.Items[0].Deleted == nil {
+			t.Fatalf("expected soft deleted item to be listed with deleted time, got: %+v", page.Items)
+		}
+
+		err = repo.Restore(ctx, "1")
+		if err != nil {
+			t.Fatalf("failed to restore item: %s", err.Error())
+		}
+
+		_, err = repo.Get(ctx, "1")
+		if err != nil {
+			t.Fatalf("expected restored item, got: %s", err.Error())
+		}
+
+		err = repo.HardDelete(ctx, "1")
+		if err != nil {
+			t.Fatalf("failed to delete item: %s", err.Error())
+		}
+
+		page, err = repo.List(ctx, database.ListOptions{IncludeDeleted: true})
+		if err != nil {
+			t.Fatalf("failed to list items: %s", err.Error())
+		}
+
+		if !slices.Equal(ids(page.Items), []string{"2"}) {
+			t.Fatalf("expected item to be deleted, got: %v", ids(page.Items))
+		}
+	})
+}
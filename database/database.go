@@ -3,7 +3,12 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"sync/atomic"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq" // PostgreSQL driver
@@ -11,22 +16,67 @@ import (
 
 // Database represents a database connection with migration capabilities.
 type Database struct {
-	conn         *sqlx.DB
-	repositories map[string]any
-	migrators    map[string]migrator
-	service      *service
+	conn              *sqlx.DB
+	replicas          []*sqlx.DB
+	nextReplica       atomic.Uint64 // Counter of reads routed to replicas, picks the next replica
+	dialect           Dialect
+	repositories      map[string]any
+	repositoryConfigs map[string]repositoryConfig
+	registrationOrder []string
+	migrators         map[string]migrator
+	service           *service
 }
 
 // New creates a new Database instance with the given connection string.
-func New(connection string) (*Database, error) {
-	db, err := sqlx.Connect("postgres", connection)
+// Options configure the dialect, the connection pool, connecting and read replicas.
+func New(connection string, opts ...Option) (*Database, error) {
+	config := config{dialect: Postgres{}, connectBackoff: DefaultConnectBackoff}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	// Named queries are bound by driver name, which sqlx may not know for the dialect
+	sqlx.BindDriver(config.dialect.DriverName(), config.dialect.BindType())
+
+	db, err := connect(connection, config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	repository := newRepository(db)
+	replicas := make([]*sqlx.DB, 0, len(config.replicas))
+	for i, replicaConnection := range config.replicas {
+		replica, err := connect(replicaConnection, config)
+		if err != nil {
+			_ = closeAll(append(replicas, db))
+			return nil, fmt.Errorf("failed to connect to replica %d: %w", i, err)
+		}
+		replicas = append(replicas, replica)
+	}
+
+	repository := newRepository(db, config.dialect)
 	service := newService(repository)
-	return &Database{conn: db, repositories: make(map[string]any), migrators: make(map[string]migrator), service: service}, nil
+	return &Database{conn: db, replicas: replicas, dialect: config.dialect, repositories: make(map[string]any), repositoryConfigs: make(map[string]repositoryConfig), migrators: make(map[string]migrator), service: service}, nil
+}
+
+// Close closes connections to the database and its replicas.
+func (db *Database) Close() error {
+	return closeAll(append([]*sqlx.DB{db.conn}, db.replicas...))
+}
+
+func closeAll(conns []*sqlx.DB) error {
+	var errs []error
+	for _, conn := range conns {
+		err := conn.Close()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to close connection: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Dialect returns the dialect of the database.
+func (db *Database) Dialect() Dialect {
+	return db.dialect
 }
 
 // Connection returns the underlying sqlx database connection.
@@ -34,43 +84,96 @@ func (db *Database) Connection() *sqlx.DB {
 	return db.conn
 }
 
+// Replicas returns the underlying sqlx connections to read replicas in the order they were configured.
+func (db *Database) Replicas() []*sqlx.DB {
+	return slices.Clone(db.replicas)
+}
+
 // RegisterRepository registers a repository in the database.
 // If repository implements migrator interface, it will migrate when `Migrate` is called.
-func (db *Database) RegisterRepository(name string, repository any) {
+// Options can be used to declare repositories that have to be migrated first.
+func (db *Database) RegisterRepository(name string, repository any, opts ...RepositoryOption) {
+	config := repositoryConfig{}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	if _, ok := db.repositories[name]; !ok {
+		db.registrationOrder = append(db.registrationOrder, name)
+	}
 	db.repositories[name] = repository
+	db.repositoryConfigs[name] = config
 
 	if migr, ok := repository.(migrator); ok {
 		db.migrators[name] = migr
+	} else {
+		delete(db.migrators, name)
 	}
 }
 
-// Migrate runs all pending migrations for registered repositories.
-func (db *Database) Migrate(ctx context.Context) error {
-	// Ensure that migration table exists
-	err := db.service.migrateSelf(ctx)
+// Migrate runs all pending migrations for registered repositories, see MigrateUp.
+//
+// Migrate operations take the migration lock of the dialect, so when several instances of the application
+// start at once only one of them migrates while the others wait for it up to the lock timeout.
+func (db *Database) Migrate(ctx context.Context, opts ...MigrateOption) error {
+	_, err := db.MigrateUp(ctx, opts...)
+	return err
+}
+
+// Repositories returns names of all registered repositories in alphabetical order.
+func (db *Database) Repositories() []string {
+	return slices.Sorted(maps.Keys(db.repositories))
+}
+
+// MigrationStatus describes a migration of a registered repository and whether it is applied.
+type MigrationStatus struct {
+	Repository string     `json:"repository"`
+	ID         string     `json:"id"`
+	Applied    bool       `json:"applied"`
+	AppliedAt  *time.Time `json:"appliedAt,omitempty"`
+	Changed    bool       `json:"changed,omitempty"` // Applied migration was edited since
+	Missing    bool       `json:"missing,omitempty"` // Applied migration is not registered anymore
+}
+
+// MigrationStatus returns applied and pending migrations of all registered repositories
+// in the order they are applied by MigrateUp, followed by applied migrations that are not registered anymore.
+func (db *Database) MigrationStatus(ctx context.Context) ([]MigrationStatus, error) {
+	migrations, err := db.orderedMigrations()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Get completed migrations
-	migrationLogs, err := db.service.getMigrationLogs(ctx)
+	migrationLogs, err := db.service.appliedMigrationLogs(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to select migrations state: %w", err)
+		return nil, fmt.Errorf("failed to select migrations state: %w", err)
 	}
 
-	// Get migrations from all migrators
-	migrations := []Migration{}
-	for name, migrator := range db.migrators {
-		for _, migr := range migrator.Migrations() {
-			migr.repository = name
-			migrations = append(migrations, migr)
+	statuses := []MigrationStatus{}
+	for _, migr := range migrations {
+		status := MigrationStatus{Repository: migr.repository, ID: migr.ID}
+
+		logIndex := slices.IndexFunc(migrationLogs, func(l migrationLog) bool {
+			return l.Repository == migr.repository && l.MigrationID == migr.ID
+		})
+		if logIndex >= 0 {
+			l := migrationLogs[logIndex]
+			status.Applied = true
+			status.AppliedAt = &l.Timestamp
+			status.Changed = l.Checksum.Valid && l.Checksum.String != migr.Checksum()
 		}
+
+		statuses = append(statuses, status)
 	}
 
-	err = db.service.applyMigrations(ctx, migrations, migrationLogs)
-	if err != nil {
-		return err
+	for _, l := range migrationLogs {
+		if l.Repository == selfRepository || slices.ContainsFunc(migrations, func(m Migration) bool {
+			return m.repository == l.Repository && m.ID == l.MigrationID
+		}) {
+			continue
+		}
+
+		statuses = append(statuses, MigrationStatus{Repository: l.Repository, ID: l.MigrationID, Applied: true, AppliedAt: &l.Timestamp, Missing: true})
 	}
 
-	return nil
+	return statuses, nil
 }
@@ -2,10 +2,13 @@ package database_test
 
 import (
 	"context"
+	"errors"
 	"slices"
 	"testing"
+	"testing/fstest"
 	"time"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/platforma-dev/platforma/database"
 	"github.com/testcontainers/testcontainers-go/modules/postgres"
 )
@@ -66,16 +69,21 @@ func TestMigrate(t *testing.T) {
 			t.Fatalf("expected no errors, got: %s", err.Error())
 		}
 
-		if len(migrationLogs) != 1 {
-			t.Fatalf("expected single migration, got: %d", len(migrationLogs))
+		// 3 = platforma_migrations init, checksum and primary key
+		if len(migrationLogs) != 3 {
+			t.Fatalf("expected 3 migrations, got: %d", len(migrationLogs))
 		}
 
-		if migrationLogs[0].Repository != "platforma_migration" {
-			t.Fatalf("expected repository to be platforma_migration, got: %s", migrationLogs[0].Repository)
+		for _, log := range migrationLogs {
+			if log.Repository != "platforma_migration" {
+				t.Fatalf("expected repository to be platforma_migration, got: %s", log.Repository)
+			}
 		}
 
-		if migrationLogs[0].MigrationID != "init" {
-			t.Fatalf("expected migration id to be init, got: %s", migrationLogs[0].MigrationID)
+		if !slices.ContainsFunc(migrationLogs, func(log migrationLog) bool {
+			return log.MigrationID == "init"
+		}) {
+			t.Fatalf("expected migration log to contain init migration, got: %v", migrationLogs)
 		}
 	})
 
@@ -116,16 +124,21 @@ func TestMigrate(t *testing.T) {
 			t.Fatalf("expected no errors, got: %s", err.Error())
 		}
 
-		if len(migrationLogs) != 1 {
-			t.Fatalf("expected single migration, got: %d", len(migrationLogs))
+		// 3 = platforma_migrations init, checksum and primary key
+		if len(migrationLogs) != 3 {
+			t.Fatalf("expected 3 migrations, got: %d", len(migrationLogs))
 		}
 
-		if migrationLogs[0].Repository != "platforma_migration" {
-			t.Fatalf("expected repository to be platforma_migration, got: %s", migrationLogs[0].Repository)
+		for _, log := range migrationLogs {
+			if log.Repository != "platforma_migration" {
+				t.Fatalf("expected repository to be platforma_migration, got: %s", log.Repository)
+			}
 		}
 
-		if migrationLogs[0].MigrationID != "init" {
-			t.Fatalf("expected migration id to be init, got: %s", migrationLogs[0].MigrationID)
+		if !slices.ContainsFunc(migrationLogs, func(log migrationLog) bool {
+			return log.MigrationID == "init"
+		}) {
+			t.Fatalf("expected migration log to contain init migration, got: %v", migrationLogs)
 		}
 	})
 
@@ -163,9 +176,9 @@ func TestMigrate(t *testing.T) {
 			t.Fatalf("expected no errors, got: %s", err.Error())
 		}
 
-		// 2 = platforma_migrations + simple_repo
-		if len(migrationLogs) != 2 {
-			t.Fatalf("expected 2 migrations, got: %d", len(migrationLogs))
+		// 4 = platforma_migrations + simple_repo
+		if len(migrationLogs) != 4 {
+			t.Fatalf("expected 4 migrations, got: %d", len(migrationLogs))
 		}
 
 		if !slices.ContainsFunc(migrationLogs, func(log migrationLog) bool {
@@ -220,9 +233,9 @@ func TestMigrate(t *testing.T) {
 			t.Fatalf("expected no errors, got: %s", err.Error())
 		}
 
-		// 3 = platforma_migrations + repos
-		if len(migrationLogs) != 3 {
-			t.Fatalf("expected 3 migrations, got: %d", len(migrationLogs))
+		// 5 = platforma_migrations + repos
+		if len(migrationLogs) != 5 {
+			t.Fatalf("expected 5 migrations, got: %d", len(migrationLogs))
 		}
 
 		if !slices.ContainsFunc(migrationLogs, func(log migrationLog) bool {
@@ -239,7 +252,7 @@ func TestMigrate(t *testing.T) {
 		if !slices.ContainsFunc(migrationLogs, func(log migrationLog) bool {
 			return log.Repository == "other_repo" && log.MigrationID == "init"
 		}) {
-			t.Fatalf("expected migration log to contain init migration for other_repo, but only got: %s", migrationLogs)
+			t.Fatalf("expected migration log to contain init migration for other_repo, but only got: %v", migrationLogs)
 		}
 
 		_, err = db.Connection().ExecContext(ctx, "SELECT * FROM other_repo")
@@ -248,6 +261,48 @@ func TestMigrate(t *testing.T) {
 		}
 	})
 
+	t.Run("health", func(t *testing.T) {
+		t.Cleanup(func() {
+			err = ctr.Restore(ctx)
+			if err != nil {
+				t.Fatalf("failed to restore db: %s", err.Error())
+			}
+		})
+
+		db, err := database.New(dbURL)
+		if err != nil {
+			t.Fatalf("failed to initialize database: %s", err.Error())
+		}
+
+		db.RegisterRepository("some_repo", simpleRepo{migrations: []database.Migration{{
+			ID:   "init",
+			Up:   "CREATE TABLE IF NOT EXISTS simple_repo (id TEXT)",
+			Down: "DROP TABLE simple_repo",
+		}}})
+
+		err = db.Migrate(ctx)
+		if err != nil {
+			t.Fatalf("failed to migrate database: %s", err.Error())
+		}
+
+		health, err := db.Health(ctx)
+		if err != nil {
+			t.Fatalf("expected no errors, got: %s", err.Error())
+		}
+
+		if health.PingLatency <= 0 {
+			t.Fatalf("expected ping latency to be measured, got: %s", health.PingLatency)
+		}
+
+		if health.OpenConnections < 1 {
+			t.Fatalf("expected open connections, got: %d", health.OpenConnections)
+		}
+
+		if health.Migrations["some_repo"].ID != "init" {
+			t.Fatalf("expected last migration of some_repo to be init, got: %+v", health.Migrations)
+		}
+	})
+
 	t.Run("migrate database with failing migration", func(t *testing.T) {
 		t.Cleanup(func() {
 			err = ctr.Restore(ctx)
@@ -293,16 +348,21 @@ func TestMigrate(t *testing.T) {
 			t.Fatalf("expected no errors, got: %s", err.Error())
 		}
 
-		if len(migrationLogs) != 1 {
-			t.Fatalf("expected 1 migration, got: %d", len(migrationLogs))
+		// 3 = platforma_migrations init, checksum and primary key
+		if len(migrationLogs) != 3 {
+			t.Fatalf("expected 3 migrations, got: %d", len(migrationLogs))
 		}
 
-		if migrationLogs[0].Repository != "platforma_migration" {
-			t.Fatalf("expected repository to be platforma_migration, got: %s", migrationLogs[0].Repository)
+		for _, log := range migrationLogs {
+			if log.Repository != "platforma_migration" {
+				t.Fatalf("expected repository to be platforma_migration, got: %s", log.Repository)
+			}
 		}
 
-		if migrationLogs[0].MigrationID != "init" {
-			t.Fatalf("expected migration id to be init, got: %s", migrationLogs[0].MigrationID)
+		if !slices.ContainsFunc(migrationLogs, func(log migrationLog) bool {
+			return log.MigrationID == "init"
+		}) {
+			t.Fatalf("expected migration log to contain init migration, got: %v", migrationLogs)
 		}
 
 		// because migration should be reverted
@@ -321,7 +381,7 @@ func TestMigrate(t *testing.T) {
 		if slices.ContainsFunc(migrationLogs, func(log migrationLog) bool {
 			return log.Repository == "other_repo" && log.MigrationID == "init"
 		}) {
-			t.Fatalf("expected migration log to not contain init migration for other_repo, but only got: %s", migrationLogs)
+			t.Fatalf("expected migration log to not contain init migration for other_repo, but only got: %v", migrationLogs)
 		}
 
 		_, err = db.Connection().ExecContext(ctx, "SELECT * FROM other_repo")
@@ -375,30 +435,28 @@ func TestMigrate(t *testing.T) {
 			t.Fatalf("expected no errors, got: %s", err.Error())
 		}
 
-		if len(migrationLogs) != 1 {
-			t.Fatalf("expected 1 migration, got: %d", len(migrationLogs))
+		// 4 = platforma_migrations + some_repo
+		if len(migrationLogs) != 4 {
+			t.Fatalf("expected 4 migrations, got: %d", len(migrationLogs))
 		}
 
-		if migrationLogs[0].Repository != "platforma_migration" {
-			t.Fatalf("expected repository to be platforma_migration, got: %s", migrationLogs[0].Repository)
-		}
-
-		if migrationLogs[0].MigrationID != "init" {
-			t.Fatalf("expected migration id to be init, got: %s", migrationLogs[0].MigrationID)
-		}
-
-		// because migration should be reverted or not even attempted
-		if slices.ContainsFunc(migrationLogs, func(log migrationLog) bool {
+		// because migration failed to revert and is still applied
+		if !slices.ContainsFunc(migrationLogs, func(log migrationLog) bool {
 			return log.Repository == "some_repo" && log.MigrationID == "init"
 		}) {
-			t.Fatalf("expected migration log to not contain init migration for some_repo")
+			t.Fatalf("expected migration log to contain init migration for some_repo")
+		}
+
+		_, err = db.Connection().ExecContext(ctx, "SELECT * FROM simple_repo")
+		if err != nil {
+			t.Fatalf("expected no errors, got: %s", err.Error())
 		}
 
 		// because migration should be reverted
 		if slices.ContainsFunc(migrationLogs, func(log migrationLog) bool {
 			return log.Repository == "other_repo" && log.MigrationID == "init"
 		}) {
-			t.Fatalf("expected migration log to not contain init migration for other_repo, but only got: %s", migrationLogs)
+			t.Fatalf("expected migration log to not contain init migration for other_repo, but only got: %v", migrationLogs)
 		}
 
 		_, err = db.Connection().ExecContext(ctx, "SELECT * FROM other_repo")
@@ -406,12 +464,563 @@ func TestMigrate(t *testing.T) {
 			t.Fatalf("expected error, got nill")
 		}
 	})
+
+	t.Run("migration outside of transaction", func(t *testing.T) {
+		t.Cleanup(func() {
+			err = ctr.Restore(ctx)
+			if err != nil {
+				t.Fatalf("failed to restore db: %s", err.Error())
+			}
+		})
+
+		db, err := database.New(dbURL)
+		if err != nil {
+			t.Fatalf("failed to initialize database: %s", err.Error())
+		}
+
+		db.RegisterRepository("some_repo", simpleRepo{migrations: []database.Migration{{
+			ID:   "init",
+			Up:   "CREATE TABLE simple_repo (id TEXT)",
+			Down: "DROP TABLE simple_repo",
+		}, {
+			ID:   "add_index",
+			Up:   "CREATE INDEX CONCURRENTLY simple_repo_id_idx ON simple_repo (id)",
+			Down: "DROP INDEX CONCURRENTLY simple_repo_id_idx",
+		}}})
+
+		err = db.Migrate(ctx)
+		if err == nil {
+			t.Fatalf("migration expected to fail in transaction")
+		}
+
+		db.RegisterRepository("some_repo", simpleRepo{migrations: []database.Migration{{
+			ID:   "init",
+			Up:   "CREATE TABLE simple_repo (id TEXT)",
+			Down: "DROP TABLE simple_repo",
+		}, {
+			ID:            "add_index",
+			Up:            "CREATE INDEX CONCURRENTLY simple_repo_id_idx ON simple_repo (id)",
+			Down:          "DROP INDEX CONCURRENTLY simple_repo_id_idx",
+			NoTransaction: true,
+		}}})
+
+		err = db.Migrate(ctx)
+		if err != nil {
+			t.Fatalf("failed to migrate database: %s", err.Error())
+		}
+
+		var migrationLogs []migrationLog
+		err = db.Connection().SelectContext(ctx, &migrationLogs, "SELECT * FROM platforma_migrations")
+		if err != nil {
+			t.Fatalf("expected no errors, got: %s", err.Error())
+		}
+
+		// 5 = platforma_migrations + 2 migrations of some_repo
+		if len(migrationLogs) != 5 {
+			t.Fatalf("expected 5 migrations, got: %d", len(migrationLogs))
+		}
+
+		_, err = db.MigrateDown(ctx, 1)
+		if err != nil {
+			t.Fatalf("failed to roll back database: %s", err.Error())
+		}
+	})
+
+	t.Run("concurrent migrations", func(t *testing.T) {
+		t.Cleanup(func() {
+			err = ctr.Restore(ctx)
+			if err != nil {
+				t.Fatalf("failed to restore db: %s", err.Error())
+			}
+		})
+
+		errs := make(chan error, 3)
+		for range 3 {
+			db, err := database.New(dbURL)
+			if err != nil {
+				t.Fatalf("failed to initialize database: %s", err.Error())
+			}
+
+			db.RegisterRepository("some_repo", simpleRepo{migrations: []database.Migration{{
+				ID:   "init",
+				Up:   "CREATE TABLE simple_repo (id TEXT)",
+				Down: "DROP TABLE simple_repo",
+			}}})
+
+			go func() {
+				errs <- db.Migrate(ctx)
+			}()
+		}
+
+		for range 3 {
+			err := <-errs
+			if err != nil {
+				t.Fatalf("failed to migrate database: %s", err.Error())
+			}
+		}
+
+		db, err := database.New(dbURL)
+		if err != nil {
+			t.Fatalf("failed to initialize database: %s", err.Error())
+		}
+
+		var migrationLogs []migrationLog
+		err = db.Connection().SelectContext(ctx, &migrationLogs, "SELECT * FROM platforma_migrations WHERE repository = 'some_repo'")
+		if err != nil {
+			t.Fatalf("expected no errors, got: %s", err.Error())
+		}
+
+		if len(migrationLogs) != 1 {
+			t.Fatalf("expected migration to be applied once, got: %d", len(migrationLogs))
+		}
+	})
+
+	t.Run("migration lock timeout", func(t *testing.T) {
+		t.Cleanup(func() {
+			err = ctr.Restore(ctx)
+			if err != nil {
+				t.Fatalf("failed to restore db: %s", err.Error())
+			}
+		})
+
+		slowDB, err := database.New(dbURL)
+		if err != nil {
+			t.Fatalf("failed to initialize database: %s", err.Error())
+		}
+
+		slowDB.RegisterRepository("some_repo", simpleRepo{migrations: []database.Migration{{
+			ID:   "slow",
+			Up:   "SELECT pg_sleep(3)",
+			Down: "SELECT 1",
+		}}})
+
+		slowErr := make(chan error, 1)
+		go func() {
+			slowErr <- slowDB.Migrate(ctx)
+		}()
+
+		// Let the slow migration take the lock
+		time.Sleep(time.Second)
+
+		db, err := database.New(dbURL)
+		if err != nil {
+			t.Fatalf("failed to initialize database: %s", err.Error())
+		}
+
+		err = db.Migrate(ctx, database.LockTimeout(100*time.Millisecond))
+		if !errors.Is(err, database.ErrMigrationLockTimeout) {
+			t.Fatalf("expected ErrMigrationLockTimeout, got: %v", err)
+		}
+
+		err = <-slowErr
+		if err != nil {
+			t.Fatalf("failed to migrate database: %s", err.Error())
+		}
+	})
+
+	t.Run("migration with single connection", func(t *testing.T) {
+		t.Cleanup(func() {
+			err = ctr.Restore(ctx)
+			if err != nil {
+				t.Fatalf("failed to restore db: %s", err.Error())
+			}
+		})
+
+		db, err := database.New(dbURL, database.WithMaxOpenConns(1))
+		if err != nil {
+			t.Fatalf("failed to initialize database: %s", err.Error())
+		}
+
+		db.RegisterRepository("some_repo", simpleRepo{migrations: []database.Migration{{
+			ID:   "init",
+			Up:   "CREATE TABLE items (id TEXT PRIMARY KEY)",
+			Down: "DROP TABLE items",
+		}}})
+
+		// The locked connection is the only one, so migrations waiting for another one would block until the timeout
+		migrateCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		err = db.Migrate(migrateCtx)
+		if err != nil {
+			t.Fatalf("failed to migrate database: %s", err.Error())
+		}
+	})
+
+	t.Run("migration drift", func(t *testing.T) {
+		t.Cleanup(func() {
+			err = ctr.Restore(ctx)
+			if err != nil {
+				t.Fatalf("failed to restore db: %s", err.Error())
+			}
+		})
+
+		db, err := database.New(dbURL)
+		if err != nil {
+			t.Fatalf("failed to initialize database: %s", err.Error())
+		}
+
+		db.RegisterRepository("some_repo", simpleRepo{migrations: []database.Migration{{
+			ID:   "init",
+			Up:   "CREATE TABLE simple_repo (id TEXT)",
+			Down: "DROP TABLE simple_repo",
+		}, {
+			ID:   "add_name",
+			Up:   "ALTER TABLE simple_repo ADD COLUMN name TEXT",
+			Down: "ALTER TABLE simple_repo DROP COLUMN name",
+		}}})
+
+		err = db.Migrate(ctx)
+		if err != nil {
+			t.Fatalf("failed to migrate database: %s", err.Error())
+		}
+
+		// init is edited and add_name is removed
+		db.RegisterRepository("some_repo", simpleRepo{migrations: []database.Migration{{
+			ID:   "init",
+			Up:   "CREATE TABLE simple_repo (id TEXT PRIMARY KEY)",
+			Down: "DROP TABLE simple_repo",
+		}}})
+
+		err = db.Migrate(ctx)
+		if !errors.Is(err, database.ErrMigrationChanged) || !errors.Is(err, database.ErrMigrationMissing) {
+			t.Fatalf("expected ErrMigrationChanged and ErrMigrationMissing, got: %v", err)
+		}
+
+		err = db.Migrate(ctx, database.AllowDrift())
+		if err != nil {
+			t.Fatalf("expected drift to be allowed, got: %s", err.Error())
+		}
+
+		statuses, err := db.MigrationStatus(ctx)
+		if err != nil {
+			t.Fatalf("expected no errors, got: %s", err.Error())
+		}
+
+		if len(statuses) != 2 || !statuses[0].Changed || !statuses[1].Missing || statuses[1].ID != "add_name" {
+			t.Fatalf("expected changed init and missing add_name, got: %+v", statuses)
+		}
+	})
+
+	t.Run("file and function migrations", func(t *testing.T) {
+		t.Cleanup(func() {
+			err = ctr.Restore(ctx)
+			if err != nil {
+				t.Fatalf("failed to restore db: %s", err.Error())
+			}
+		})
+
+		db, err := database.New(dbURL)
+		if err != nil {
+			t.Fatalf("failed to initialize database: %s", err.Error())
+		}
+
+		migrations, err := database.LoadMigrations(fstest.MapFS{
+			"migrations/001_init.up.sql":   {Data: []byte("CREATE TABLE simple_repo (id TEXT, name TEXT); INSERT INTO simple_repo (id) VALUES ('1')")},
+			"migrations/001_init.down.sql": {Data: []byte("DROP TABLE simple_repo")},
+		}, "migrations")
+		if err != nil {
+			t.Fatalf("failed to load migrations: %s", err.Error())
+		}
+
+		db.RegisterRepository("some_repo", simpleRepo{migrations: append(migrations, database.Migration{
+			ID: "002_backfill_names",
+			UpFunc: func(ctx context.Context, tx *sqlx.Tx) error {
+				_, err := tx.ExecContext(ctx, "UPDATE simple_repo SET name = 'user ' || id")
+				return err
+			},
+			DownFunc: func(ctx context.Context, tx *sqlx.Tx) error {
+				_, err := tx.ExecContext(ctx, "UPDATE simple_repo SET name = NULL")
+				return err
+			},
+		})})
+
+		err = db.Migrate(ctx)
+		if err != nil {
+			t.Fatalf("failed to migrate database: %s", err.Error())
+		}
+
+		var name string
+		err = db.Connection().GetContext(ctx, &name, "SELECT name FROM simple_repo WHERE id = '1'")
+		if err != nil {
+			t.Fatalf("expected no errors, got: %s", err.Error())
+		}
+
+		if name != "user 1" {
+			t.Fatalf("expected name to be backfilled, got: %s", name)
+		}
+
+		steps, err := db.MigrateDown(ctx, 2)
+		if err != nil {
+			t.Fatalf("failed to roll back database: %s", err.Error())
+		}
+
+		if len(steps) != 2 || steps[0].ID != "002_backfill_names" || steps[1].ID != "001_init" {
+			t.Fatalf("expected both migrations to be rolled back, got: %v", steps)
+		}
+	})
+
+	t.Run("invalid migration", func(t *testing.T) {
+		db, err := database.New(dbURL)
+		if err != nil {
+			t.Fatalf("failed to initialize database: %s", err.Error())
+		}
+
+		db.RegisterRepository("some_repo", simpleRepo{migrations: []database.Migration{{
+			ID:            "backfill",
+			UpFunc:        func(_ context.Context, _ *sqlx.Tx) error { return nil },
+			NoTransaction: true,
+		}}})
+
+		err = db.Migrate(ctx)
+		if !errors.Is(err, database.ErrInvalidMigration) {
+			t.Fatalf("expected ErrInvalidMigration, got: %v", err)
+		}
+	})
+
+	t.Run("migrate repositories in dependency order", func(t *testing.T) {
+		t.Cleanup(func() {
+			err = ctr.Restore(ctx)
+			if err != nil {
+				t.Fatalf("failed to restore db: %s", err.Error())
+			}
+		})
+
+		db, err := database.New(dbURL)
+		if err != nil {
+			t.Fatalf("failed to initialize database: %s", err.Error())
+		}
+
+		db.RegisterRepository("orders_repo", simpleRepo{migrations: []database.Migration{{
+			ID:   "init",
+			Up:   "CREATE TABLE orders (id TEXT PRIMARY KEY, user_id TEXT REFERENCES users (id))",
+			Down: "DROP TABLE orders",
+		}}}, database.DependsOn("users_repo"))
+
+		db.RegisterRepository("users_repo", simpleRepo{migrations: []database.Migration{{
+			ID:   "init",
+			Up:   "CREATE TABLE users (id TEXT PRIMARY KEY)",
+			Down: "DROP TABLE users",
+		}}})
+
+		steps, err := db.MigrateUp(ctx)
+		if err != nil {
+			t.Fatalf("failed to migrate database: %s", err.Error())
+		}
+
+		expected := []database.MigrationStep{
+			{Repository: "users_repo", ID: "init", Direction: database.MigrationUp},
+			{Repository: "orders_repo", ID: "init", Direction: database.MigrationUp},
+		}
+		if !slices.Equal(steps, expected) {
+			t.Fatalf("expected steps %v, got: %v", expected, steps)
+		}
+
+		steps, err = db.MigrateDown(ctx, 2)
+		if err != nil {
+			t.Fatalf("failed to roll back database: %s", err.Error())
+		}
+
+		expected = []database.MigrationStep{
+			{Repository: "orders_repo", ID: "init", Direction: database.MigrationDown},
+			{Repository: "users_repo", ID: "init", Direction: database.MigrationDown},
+		}
+		if !slices.Equal(steps, expected) {
+			t.Fatalf("expected steps %v, got: %v", expected, steps)
+		}
+
+		_, err = db.Connection().ExecContext(ctx, "SELECT * FROM users")
+		if err == nil {
+			t.Fatalf("expected error, got nill")
+		}
+	})
+
+	t.Run("repository dependency cycle", func(t *testing.T) {
+		db, err := database.New(dbURL)
+		if err != nil {
+			t.Fatalf("failed to initialize database: %s", err.Error())
+		}
+
+		db.RegisterRepository("some_repo", simpleRepo{}, database.DependsOn("other_repo"))
+		db.RegisterRepository("other_repo", simpleRepo{}, database.DependsOn("some_repo"))
+
+		_, err = db.MigrateUp(ctx)
+		if !errors.Is(err, database.ErrRepositoryDependencyCycle) {
+			t.Fatalf("expected ErrRepositoryDependencyCycle, got: %v", err)
+		}
+	})
+
+	t.Run("migrate down, to and redo", func(t *testing.T) {
+		t.Cleanup(func() {
+			err = ctr.Restore(ctx)
+			if err != nil {
+				t.Fatalf("failed to restore db: %s", err.Error())
+			}
+		})
+
+		db, err := database.New(dbURL)
+		if err != nil {
+			t.Fatalf("failed to initialize database: %s", err.Error())
+		}
+
+		db.RegisterRepository("some_repo", simpleRepo{migrations: []database.Migration{{
+			ID:   "init",
+			Up:   "CREATE TABLE simple_repo (id TEXT)",
+			Down: "DROP TABLE simple_repo",
+		}, {
+			ID:   "add_name",
+			Up:   "ALTER TABLE simple_repo ADD COLUMN name TEXT",
+			Down: "ALTER TABLE simple_repo DROP COLUMN name",
+		}, {
+			ID:   "add_email",
+			Up:   "ALTER TABLE simple_repo ADD COLUMN email TEXT",
+			Down: "ALTER TABLE simple_repo DROP COLUMN email",
+		}}})
+
+		err = db.Migrate(ctx)
+		if err != nil {
+			t.Fatalf("failed to migrate database: %s", err.Error())
+		}
+
+		steps, err := db.MigrateDown(ctx, 1)
+		if err != nil {
+			t.Fatalf("failed to roll back database: %s", err.Error())
+		}
+
+		if len(steps) != 1 || steps[0].ID != "add_email" {
+			t.Fatalf("expected add_email to be rolled back, got: %v", steps)
+		}
+
+		_, err = db.Connection().ExecContext(ctx, "SELECT email FROM simple_repo")
+		if err == nil {
+			t.Fatalf("expected error, got nill")
+		}
+
+		steps, err = db.MigrateTo(ctx, "some_repo/init")
+		if err != nil {
+			t.Fatalf("failed to migrate database to init: %s", err.Error())
+		}
+
+		if len(steps) != 1 || steps[0].ID != "add_name" || steps[0].Direction != database.MigrationDown {
+			t.Fatalf("expected add_name to be rolled back, got: %v", steps)
+		}
+
+		steps, err = db.MigrateTo(ctx, "add_email")
+		if err != nil {
+			t.Fatalf("failed to migrate database to add_email: %s", err.Error())
+		}
+
+		if len(steps) != 2 || steps[1].ID != "add_email" || steps[1].Direction != database.MigrationUp {
+			t.Fatalf("expected add_name and add_email to be applied, got: %v", steps)
+		}
+
+		steps, err = db.MigrateRedo(ctx)
+		if err != nil {
+			t.Fatalf("failed to redo migration: %s", err.Error())
+		}
+
+		expected := []database.MigrationStep{
+			{Repository: "some_repo", ID: "add_email", Direction: database.MigrationDown},
+			{Repository: "some_repo", ID: "add_email", Direction: database.MigrationUp},
+		}
+		if !slices.Equal(steps, expected) {
+			t.Fatalf("expected steps %v, got: %v", expected, steps)
+		}
+
+		_, err = db.Connection().ExecContext(ctx, "SELECT id, name, email FROM simple_repo")
+		if err != nil {
+			t.Fatalf("expected no errors, got: %s", err.Error())
+		}
+
+		_, err = db.MigrateTo(ctx, "missing")
+		if !errors.Is(err, database.ErrUnknownMigration) {
+			t.Fatalf("expected ErrUnknownMigration, got: %v", err)
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		t.Cleanup(func() {
+			err = ctr.Restore(ctx)
+			if err != nil {
+				t.Fatalf("failed to restore db: %s", err.Error())
+			}
+		})
+
+		db, err := database.New(dbURL)
+		if err != nil {
+			t.Fatalf("failed to initialize database: %s", err.Error())
+		}
+
+		db.RegisterRepository("some_repo", simpleRepo{migrations: []database.Migration{{
+			ID:   "init",
+			Up:   "CREATE TABLE simple_repo (id TEXT)",
+			Down: "DROP TABLE simple_repo",
+		}}})
+
+		steps, err := db.MigrateUp(ctx, database.DryRun())
+		if err != nil {
+			t.Fatalf("failed to migrate database: %s", err.Error())
+		}
+
+		if len(steps) != 1 || steps[0].ID != "init" {
+			t.Fatalf("expected init to be planned, got: %v", steps)
+		}
+
+		_, err = db.Connection().ExecContext(ctx, "SELECT * FROM simple_repo")
+		if err == nil {
+			t.Fatalf("expected error, got nill")
+		}
+
+		statuses, err := db.MigrationStatus(ctx)
+		if err != nil {
+			t.Fatalf("expected no errors, got: %s", err.Error())
+		}
+
+		if len(statuses) != 1 || statuses[0].Applied {
+			t.Fatalf("expected init to be pending, got: %+v", statuses)
+		}
+	})
+
+	t.Run("irreversible migration", func(t *testing.T) {
+		t.Cleanup(func() {
+			err = ctr.Restore(ctx)
+			if err != nil {
+				t.Fatalf("failed to restore db: %s", err.Error())
+			}
+		})
+
+		db, err := database.New(dbURL)
+		if err != nil {
+			t.Fatalf("failed to initialize database: %s", err.Error())
+		}
+
+		db.RegisterRepository("some_repo", simpleRepo{migrations: []database.Migration{{
+			ID: "init",
+			Up: "CREATE TABLE simple_repo (id TEXT)",
+		}}})
+
+		err = db.Migrate(ctx)
+		if err != nil {
+			t.Fatalf("failed to migrate database: %s", err.Error())
+		}
+
+		_, err = db.MigrateDown(ctx, 1)
+		if !errors.Is(err, database.ErrIrreversibleMigration) {
+			t.Fatalf("expected ErrIrreversibleMigration, got: %v", err)
+		}
+
+		_, err = db.Connection().ExecContext(ctx, "SELECT * FROM simple_repo")
+		if err != nil {
+			t.Fatalf("expected no errors, got: %s", err.Error())
+		}
+	})
 }
 
 type migrationLog struct {
 	Repository  string    `db:"repository"`
 	MigrationID string    `db:"id"`
 	Timestamp   time.Time `db:"timestamp"`
+	Checksum    *string   `db:"checksum"`
 }
 
 type simpleRepo struct {
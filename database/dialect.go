@@ -0,0 +1,220 @@
+package database
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+)
+
+// Names of the built-in dialects, used to select migrations with Migration.Dialects.
+const (
+	DialectPostgres = "postgres"
+	DialectMySQL    = "mysql"
+	DialectSQLite   = "sqlite"
+)
+
+// Dialect describes the SQL database behind a connection. The database/sql driver of the dialect
+// has to be imported by the application, except for the PostgreSQL and MySQL drivers that the package imports itself.
+//
+// Queries run through the Database may use $1, $2, ... placeholders, which are rebound to
+// the placeholders of the dialect.
+type Dialect interface {
+	// Name is the name migrations are selected by, see Migration.Dialects.
+	Name() string
+	// DriverName is the name of the database/sql driver to connect with.
+	DriverName() string
+	// BindType is the sqlx bind type of placeholders, e.g. sqlx.DOLLAR or sqlx.QUESTION.
+	BindType() int
+	// MigrationLockQueries returns the query that tries to take the migration lock and returns whether it was taken,
+	// and the query that releases it. Migrations are not locked if the lock query is empty.
+	MigrationLockQueries() (lock, unlock string)
+	// IsRetryable tells whether a transaction failed with the error can be retried, e.g. after a deadlock.
+	IsRetryable(err error) bool
+	// QuoteIdentifier quotes the table or column name, so names like "user" can be used.
+	QuoteIdentifier(name string) string
+}
+
+// Postgres is the PostgreSQL dialect. It is used by default.
+type Postgres struct {
+	Driver string // Name of the database/sql driver, "postgres" of github.com/lib/pq by default
+}
+
+// Name returns DialectPostgres.
+func (Postgres) Name() string { return DialectPostgres }
+
+// DriverName returns the name of the driver, "postgres" by default.
+func (d Postgres) DriverName() string { return driverOrDefault(d.Driver, "postgres") }
+
+// BindType returns sqlx.DOLLAR.
+func (Postgres) BindType() int { return sqlx.DOLLAR }
+
+// MigrationLockQueries returns queries of a session level advisory lock.
+func (Postgres) MigrationLockQueries() (string, string) {
+	id := strconv.FormatInt(migrationLockID, 10)
+	return "SELECT pg_try_advisory_lock(" + id + ")", "SELECT pg_advisory_unlock(" + id + ")"
+}
+
+// IsRetryable reports serialization failures and deadlocks.
+func (Postgres) IsRetryable(err error) bool {
+	return hasSQLState(err, "40001", "40P01")
+}
+
+// QuoteIdentifier quotes the name with double quotes.
+func (Postgres) QuoteIdentifier(name string) string { return quoteIdentifier(name, '"') }
+
+// MySQL is the MySQL dialect. The connection string has to enable multiStatements and parseTime
+// and include ANSI_QUOTES in sql_mode, as built-in repositories quote identifiers with double quotes.
+// CRUDRepository also needs clientFoundRows, so that updates that don't change values report the matched row.
+// Note that MySQL commits DDL statements implicitly, so failed migrations may be applied partially.
+type MySQL struct {
+	Driver string // Name of the database/sql driver, "mysql" of github.com/go-sql-driver/mysql by default
+}
+
+// Name returns DialectMySQL.
+func (MySQL) Name() string { return DialectMySQL }
+
+// DriverName returns the name of the driver, "mysql" by default.
+func (d MySQL) DriverName() string { return driverOrDefault(d.Driver, "mysql") }
+
+// BindType returns sqlx.QUESTION.
+func (MySQL) BindType() int { return sqlx.QUESTION }
+
+// MigrationLockQueries returns queries of a named lock.
+func (MySQL) MigrationLockQueries() (string, string) {
+	return "SELECT GET_LOCK('platforma_migrations', 0)", "SELECT RELEASE_LOCK('platforma_migrations')"
+}
+
+// MySQL error numbers of transactions that can be retried.
+const (
+	mysqlLockWaitTimeout = 1205
+	mysqlDeadlock        = 1213
+)
+
+// IsRetryable reports deadlocks and lock wait timeouts.
+func (MySQL) IsRetryable(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == mysqlDeadlock || mysqlErr.Number == mysqlLockWaitTimeout
+	}
+
+	// Other drivers may expose the SQLSTATE code of deadlocks
+	return hasSQLState(err, "40001")
+}
+
+// QuoteIdentifier quotes the name with backticks.
+func (MySQL) QuoteIdentifier(name string) string { return quoteIdentifier(name, '`') }
+
+// SQLite is the SQLite dialect. SQLite locks the whole database file on writes,
+// so migrations are not locked separately.
+type SQLite struct {
+	Driver string // Name of the database/sql driver, "sqlite3" of github.com/mattn/go-sqlite3 by default
+}
+
+// Name returns DialectSQLite.
+func (SQLite) Name() string { return DialectSQLite }
+
+// DriverName returns the name of the driver, "sqlite3" by default.
+func (d SQLite) DriverName() string { return driverOrDefault(d.Driver, "sqlite3") }
+
+// BindType returns sqlx.QUESTION.
+func (SQLite) BindType() int { return sqlx.QUESTION }
+
+// MigrationLockQueries returns no queries.
+func (SQLite) MigrationLockQueries() (string, string) { return "", "" }
+
+// IsRetryable returns false, as SQLite transactions wait for locks instead of failing.
+func (SQLite) IsRetryable(error) bool { return false }
+
+// QuoteIdentifier quotes the name with double quotes.
+func (SQLite) QuoteIdentifier(name string) string { return quoteIdentifier(name, '"') }
+
+// quoteIdentifier quotes the name with the quote character, doubling the character inside the name.
+func quoteIdentifier(name string, quote byte) string {
+	q := string(quote)
+	return q + strings.ReplaceAll(name, q, q+q) + q
+}
+
+func driverOrDefault(driver, defaultDriver string) string {
+	if driver == "" {
+		return defaultDriver
+	}
+	return driver
+}
+
+// hasSQLState tells whether the error has one of the SQLSTATE codes. Drivers like lib/pq and pgx
+// expose the code with the SQLState method.
+func hasSQLState(err error, codes ...string) bool {
+	var sqlErr interface{ SQLState() string }
+	if !errors.As(err, &sqlErr) {
+		return false
+	}
+
+	for _, code := range codes {
+		if sqlErr.SQLState() == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rebind converts $1, $2, ... placeholders of the query to placeholders of the bind type,
+// reordering arguments to match. Queries of other dialects are returned as is.
+func rebind(bindType int, query string, args []any) (string, []any) {
+	if bindType == sqlx.DOLLAR || !strings.Contains(query, "$") {
+		return query, args
+	}
+
+	var b strings.Builder
+	b.Grow(len(query))
+	rebound := make([]any, 0, len(args))
+
+	converted := false
+	var quote byte // Quote character of the string or identifier the scan is in
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '$' && i+1 < len(query) && isDigit(query[i+1]):
+			end := i + 1
+			for end < len(query) && isDigit(query[end]) {
+				end++
+			}
+
+			n, err := strconv.Atoi(query[i+1 : end])
+			if err != nil || n < 1 || n > len(args) {
+				// Left for the driver to report
+				b.WriteString(query[i:end])
+				i = end - 1
+				continue
+			}
+
+			b.WriteByte('?')
+			rebound = append(rebound, args[n-1])
+			converted = true
+			i = end - 1
+			continue
+		}
+
+		b.WriteByte(c)
+	}
+
+	if !converted {
+		return query, args
+	}
+
+	return sqlx.Rebind(bindType, b.String()), rebound
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,279 @@
+package database_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/platforma-dev/platforma/database"
+)
+
+// lockingSQLite is the SQLite dialect with migration lock queries, which take a connection like the ones of other dialects.
+type lockingSQLite struct {
+	database.SQLite
+}
+
+func (lockingSQLite) MigrationLockQueries() (string, string) {
+	return "SELECT 1", "SELECT 1"
+}
+
+func TestSQLite(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	newDB := func(t *testing.T) *database.Database {
+		t.Helper()
+
+		db, err := database.New(filepath.Join(t.TempDir(), "test.db"), database.WithDialect(database.SQLite{}))
+		if err != nil {
+			t.Fatalf("failed to initialize database: %s", err.Error())
+		}
+		t.Cleanup(func() { _ = db.Connection().Close() })
+
+		return db
+	}
+
+	t.Run("migrate", func(t *testing.T) {
+		t.Parallel()
+
+		db := newDB(t)
+		db.RegisterRepository("some_repo", simpleRepo{migrations: []database.Migration{{
+			ID:   "init",
+			Up:   "CREATE TABLE items (id SERIAL PRIMARY KEY, name TEXT)",
+			Down: "DROP TABLE items",
+		}, {
+			ID:       "init",
+			Up:       "CREATE TABLE items (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT)",
+			Down:     "DROP TABLE items",
+			Dialects: []string{database.DialectSQLite},
+		}, {
+			ID:       "seed",
+			Up:       "INSERT INTO items (name) VALUES ('mysql')",
+			Dialects: []string{database.DialectMySQL},
+		}}})
+
+		err := db.Migrate(ctx)
+		if err != nil {
+			t.Fatalf("failed to migrate database: %s", err.Error())
+		}
+
+		var migrationLogs []migrationLog
+		err = db.SelectContext(ctx, &migrationLogs, "SELECT * FROM platforma_migrations")
+		if err != nil {
+			t.Fatalf("expected no errors, got: %s", err.Error())
+		}
+
+		// 2 = platforma_migrations init and some_repo init
+		if len(migrationLogs) != 2 {
+			t.Fatalf("expected 2 migrations, got: %d", len(migrationLogs))
+		}
+
+		var count int
+		err = db.GetContext(ctx, &count, "SELECT COUNT(*) FROM items")
+		if err != nil {
+			t.Fatalf("expected SQLite migration to create items table, got: %s", err.Error())
+		}
+
+		if count != 0 {
+			t.Fatalf("expected migration for other dialect to be skipped, got %d items", count)
+		}
+
+		steps, err := db.MigrateDown(ctx, 1)
+		if err != nil {
+			t.Fatalf("failed to roll back migration: %s", err.Error())
+		}
+
+		if len(steps) != 1 || steps[0].ID != "init" {
+			t.Fatalf("expected init to be rolled back, got: %v", steps)
+		}
+	})
+
+	t.Run("status and dry run of fresh database", func(t *testing.T) {
+		t.Parallel()
+
+		db := newDB(t)
+		db.RegisterRepository("some_repo", simpleRepo{migrations: []database.Migration{{
+			ID: "init",
+			Up: "CREATE TABLE items (id TEXT PRIMARY KEY)",
+		}}})
+
+		statuses, err := db.MigrationStatus(ctx)
+		if err != nil {
+			t.Fatalf("failed to get migration status: %s", err.Error())
+		}
+
+		if len(statuses) != 1 || statuses[0].ID != "init" || statuses[0].Applied {
+			t.Fatalf("expected init to be pending, got: %+v", statuses)
+		}
+
+		steps, err := db.MigrateUp(ctx, database.DryRun())
+		if err != nil {
+			t.Fatalf("failed to plan migrations: %s", err.Error())
+		}
+
+		if len(steps) != 1 || steps[0].ID != "init" {
+			t.Fatalf("expected init to be planned, got: %v", steps)
+		}
+
+		var tables int
+		err = db.GetContext(ctx, &tables, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table'")
+		if err != nil {
+			t.Fatalf("failed to count tables: %s", err.Error())
+		}
+
+		if tables != 0 {
+			t.Fatalf("expected status and dry run not to create tables, got %d tables", tables)
+		}
+	})
+
+	t.Run("migrate with single connection while locked", func(t *testing.T) {
+		t.Parallel()
+
+		db, err := database.New(
+			filepath.Join(t.TempDir(), "test.db"),
+			database.WithDialect(lockingSQLite{}),
+			database.WithMaxOpenConns(1),
+		)
+		if err != nil {
+			t.Fatalf("failed to initialize database: %s", err.Error())
+		}
+		t.Cleanup(func() { _ = db.Close() })
+
+		db.RegisterRepository("some_repo", simpleRepo{migrations: []database.Migration{{
+			ID:   "init",
+			Up:   "CREATE TABLE items (id TEXT PRIMARY KEY)",
+			Down: "DROP TABLE items",
+		}}})
+
+		// Migrations waiting for a second connection would block until the timeout
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		err = db.Migrate(ctx)
+		if err != nil {
+			t.Fatalf("failed to migrate database: %s", err.Error())
+		}
+
+		_, err = db.MigrateDown(ctx, 1)
+		if err != nil {
+			t.Fatalf("failed to roll back migration: %s", err.Error())
+		}
+	})
+
+	t.Run("rebind placeholders", func(t *testing.T) {
+		t.Parallel()
+
+		db := newDB(t)
+		_, err := db.ExecContext(ctx, `CREATE TABLE "user" (id TEXT PRIMARY KEY, name TEXT)`)
+		if err != nil {
+			t.Fatalf("failed to create table: %s", err.Error())
+		}
+
+		_, err = db.ExecContext(ctx, `INSERT INTO "user" (name, id) VALUES ($2, $1)`, "1", "alice")
+		if err != nil {
+			t.Fatalf("failed to insert user: %s", err.Error())
+		}
+
+		var name string
+		err = db.GetContext(ctx, &name, `SELECT name FROM "user" WHERE id = $1 AND name <> '$1'`, "1")
+		if err != nil {
+			t.Fatalf("failed to get user: %s", err.Error())
+		}
+
+		if name != "alice" {
+			t.Fatalf("expected alice, got: %s", name)
+		}
+	})
+
+	t.Run("transaction rollback", func(t *testing.T) {
+		t.Parallel()
+
+		db := newDB(t)
+		_, err := db.ExecContext(ctx, "CREATE TABLE items (id TEXT PRIMARY KEY)")
+		if err != nil {
+			t.Fatalf("failed to create table: %s", err.Error())
+		}
+
+		fnErr := errors.New("something went wrong")
+		err = db.WithTx(ctx, func(ctx context.Context) error {
+			_, err := db.ExecContext(ctx, "INSERT INTO items (id) VALUES ($1)", "rolled back")
+			if err != nil {
+				return err
+			}
+			return fnErr
+		})
+		if !errors.Is(err, fnErr) {
+			t.Fatalf("expected error of the function, got: %v", err)
+		}
+
+		var count int
+		err = db.GetContext(ctx, &count, "SELECT COUNT(*) FROM items")
+		if err != nil {
+			t.Fatalf("failed to count items: %s", err.Error())
+		}
+
+		if count != 0 {
+			t.Fatalf("expected item to be rolled back")
+		}
+	})
+}
+
+func TestIsRetryable(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		dialect   database.Dialect
+		err       error
+		retryable bool
+	}{
+		{name: "postgres serialization failure", dialect: database.Postgres{}, err: &pq.Error{Code: "40001"}, retryable: true},
+		{name: "postgres deadlock", dialect: database.Postgres{}, err: fmt.Errorf("wrapped: %w", &pq.Error{Code: "40P01"}), retryable: true},
+		{name: "postgres unique violation", dialect: database.Postgres{}, err: &pq.Error{Code: "23505"}, retryable: false},
+		{name: "mysql deadlock", dialect: database.MySQL{}, err: &mysql.MySQLError{Number: 1213}, retryable: true},
+		{name: "mysql lock wait timeout", dialect: database.MySQL{}, err: fmt.Errorf("wrapped: %w", &mysql.MySQLError{Number: 1205}), retryable: true},
+		{name: "mysql duplicate entry", dialect: database.MySQL{}, err: &mysql.MySQLError{Number: 1062}, retryable: false},
+		{name: "sqlite", dialect: database.SQLite{}, err: errors.New("database is locked"), retryable: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if tt.dialect.IsRetryable(tt.err) != tt.retryable {
+				t.Fatalf("expected retryable to be %v for %v", tt.retryable, tt.err)
+			}
+		})
+	}
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		dialect  database.Dialect
+		expected string
+	}{
+		{dialect: database.Postgres{}, expected: `"my""user"`},
+		{dialect: database.MySQL{}, expected: "`my\"user`"},
+		{dialect: database.SQLite{}, expected: `"my""user"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect.Name(), func(t *testing.T) {
+			t.Parallel()
+
+			quoted := tt.dialect.QuoteIdentifier(`my"user`)
+			if quoted != tt.expected {
+				t.Fatalf("expected %s, got: %s", tt.expected, quoted)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/platforma-dev/platforma/log"
+)
+
+// ErrMigrationChanged is returned when an applied migration was edited after it had been applied.
+var ErrMigrationChanged = errors.New("applied migration has changed")
+
+// ErrMigrationMissing is returned when the migration log has a migration that is not registered anymore.
+var ErrMigrationMissing = errors.New("applied migration is missing")
+
+// AllowDrift makes a migrate operation log changed and missing migrations as warnings instead of failing.
+func AllowDrift() MigrateOption {
+	return func(c *migrateConfig) {
+		c.allowDrift = true
+	}
+}
+
+// Checksum returns the SHA-256 checksum of the Up statement. It is stored in the migration log
+// when the migration is applied to detect later edits. Go function migrations have no checksum.
+func (m Migration) Checksum() string {
+	if m.UpFunc != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(m.Up))
+	return hex.EncodeToString(sum[:])
+}
+
+// detectDrift compares registered migrations with the migration log. It returns an error wrapping
+// ErrMigrationChanged for every applied migration with a different checksum and ErrMigrationMissing
+// for every logged migration that is not registered. Logs written before checksums were stored are not compared.
+func detectDrift(migrations []Migration, migrationLogs []migrationLog) error {
+	byKey := make(map[migrationKey]Migration, len(migrations))
+	for _, migr := range migrations {
+		byKey[migr.key()] = migr
+	}
+
+	drift := []error{}
+	for _, l := range migrationLogs {
+		// Migrations of the log table itself are managed by the package
+		if l.Repository == selfRepository {
+			continue
+		}
+
+		migr, ok := byKey[migrationKey{repository: l.Repository, id: l.MigrationID}]
+		if !ok {
+			drift = append(drift, fmt.Errorf("%w: %s/%s", ErrMigrationMissing, l.Repository, l.MigrationID))
+			continue
+		}
+
+		if l.Checksum.Valid && l.Checksum.String != migr.Checksum() {
+			drift = append(drift, fmt.Errorf("%w: %s/%s", ErrMigrationChanged, l.Repository, l.MigrationID))
+		}
+	}
+
+	return errors.Join(drift...)
+}
+
+// checkDrift returns the drift detected by detectDrift, or only logs it if drift is allowed.
+func checkDrift(ctx context.Context, migrations []Migration, migrationLogs []migrationLog, allowDrift bool) error {
+	err := detectDrift(migrations, migrationLogs)
+	if err == nil || !allowDrift {
+		return err
+	}
+
+	log.WarnContext(ctx, "applied migrations don't match registered migrations", "error", err)
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Health holds health information about the database connection.
+type Health struct {
+	PingLatency        time.Duration            `json:"pingLatency"`
+	MaxOpenConnections int                      `json:"maxOpenConnections"`
+	OpenConnections    int                      `json:"openConnections"`
+	InUse              int                      `json:"inUse"`
+	Idle               int                      `json:"idle"`
+	WaitCount          int64                    `json:"waitCount"`
+	WaitDuration       time.Duration            `json:"waitDuration"`
+	Migrations         map[string]LastMigration `json:"migrations,omitempty"` // Last applied migration per repository
+}
+
+// LastMigration is the last migration applied to a repository.
+type LastMigration struct {
+	ID        string    `json:"id"`
+	AppliedAt time.Time `json:"appliedAt"`
+}
+
+// Health pings the database and collects connection pool stats and the last applied migrations.
+// Pool stats are returned even if the ping fails.
+func (db *Database) Health(ctx context.Context) (*Health, error) {
+	stats := db.conn.Stats()
+	health := &Health{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDuration:       stats.WaitDuration,
+	}
+
+	startedAt := time.Now()
+	err := db.conn.PingContext(ctx)
+	health.PingLatency = time.Since(startedAt)
+	if err != nil {
+		return health, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	migrationLogs, err := db.service.getMigrationLogs(ctx)
+	if err != nil {
+		return health, err
+	}
+
+	health.Migrations = lastMigrations(migrationLogs)
+
+	return health, nil
+}
+
+// lastMigrations returns the most recent migration log for every repository.
+func lastMigrations(migrationLogs []migrationLog) map[string]LastMigration {
+	migrations := make(map[string]LastMigration)
+
+	for _, l := range migrationLogs {
+		last, ok := migrations[l.Repository]
+		if !ok || !l.Timestamp.Before(last.AppliedAt) {
+			migrations[l.Repository] = LastMigration{ID: l.MigrationID, AppliedAt: l.Timestamp}
+		}
+	}
+
+	return migrations
+}
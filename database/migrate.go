@@ -0,0 +1,398 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/platforma-dev/platforma/log"
+)
+
+// ErrUnknownRepositoryDependency is returned when a repository depends on a repository that is not registered.
+var ErrUnknownRepositoryDependency = errors.New("unknown repository dependency")
+
+// ErrRepositoryDependencyCycle is returned when repositories depend on each other in a cycle.
+var ErrRepositoryDependencyCycle = errors.New("repository dependency cycle")
+
+// ErrUnknownMigration is returned when the target migration is not found in registered repositories.
+var ErrUnknownMigration = errors.New("unknown migration")
+
+// ErrAmbiguousMigration is returned when the target migration ID exists in more than one repository.
+var ErrAmbiguousMigration = errors.New("ambiguous migration")
+
+// ErrIrreversibleMigration is returned when a migration without Down statement or DownFunc has to be rolled back.
+var ErrIrreversibleMigration = errors.New("migration can't be rolled back")
+
+// ErrNoAppliedMigrations is returned by MigrateRedo when there is no migration to redo.
+var ErrNoAppliedMigrations = errors.New("no applied migrations")
+
+// ErrMigrationLockTimeout is returned when the migration lock is not acquired within the lock timeout.
+var ErrMigrationLockTimeout = errors.New("timed out waiting for migration lock")
+
+// DefaultMigrationLockTimeout is the default time a migrate operation waits for the migration lock.
+const DefaultMigrationLockTimeout = time.Minute
+
+// RepositoryOption configures how a registered repository is migrated.
+type RepositoryOption func(*repositoryConfig)
+
+type repositoryConfig struct {
+	dependsOn []string
+}
+
+// DependsOn makes migrations of the repository run after all migrations of the given repositories
+// and roll back before them.
+func DependsOn(repositories ...string) RepositoryOption {
+	return func(c *repositoryConfig) {
+		c.dependsOn = append(c.dependsOn, repositories...)
+	}
+}
+
+// MigrationDirection tells whether a migration is applied or rolled back.
+type MigrationDirection string
+
+const (
+	MigrationUp   MigrationDirection = "up"
+	MigrationDown MigrationDirection = "down"
+)
+
+// MigrationStep is a single migration applied or rolled back by a migrate operation.
+type MigrationStep struct {
+	Repository string             `json:"repository"`
+	ID         string             `json:"id"`
+	Direction  MigrationDirection `json:"direction"`
+}
+
+func (s MigrationStep) String() string {
+	return fmt.Sprintf("%s %s/%s", s.Direction, s.Repository, s.ID)
+}
+
+// MigrateOption configures a migrate operation.
+type MigrateOption func(*migrateConfig)
+
+type migrateConfig struct {
+	dryRun      bool
+	lockTimeout time.Duration
+	allowDrift  bool
+}
+
+// DryRun makes a migrate operation return the steps it would run without running them.
+func DryRun() MigrateOption {
+	return func(c *migrateConfig) {
+		c.dryRun = true
+	}
+}
+
+// LockTimeout sets how long a migrate operation waits for the migration lock held by another instance,
+// DefaultMigrationLockTimeout by default.
+func LockTimeout(timeout time.Duration) MigrateOption {
+	return func(c *migrateConfig) {
+		c.lockTimeout = timeout
+	}
+}
+
+// MigrateUp applies all pending migrations and returns the applied steps.
+//
+// Like other migrate operations, it fails with ErrMigrationChanged or ErrMigrationMissing if applied
+// migrations were edited or removed since, see AllowDrift.
+// Repositories are migrated in registration order, except that every repository is migrated after
+// the repositories it depends on. Migrations of a repository are applied in the order they are returned by Migrations.
+// If a migration fails, migrations applied by this call are rolled back.
+func (db *Database) MigrateUp(ctx context.Context, opts ...MigrateOption) ([]MigrationStep, error) {
+	return db.migrate(ctx, opts, func(migrations []Migration, applied map[migrationKey]bool) ([]MigrationStep, error) {
+		steps := []MigrationStep{}
+		for _, migr := range migrations {
+			if !applied[migr.key()] {
+				steps = append(steps, migr.step(MigrationUp))
+			}
+		}
+
+		return steps, nil
+	})
+}
+
+// MigrateDown rolls back the given number of the latest applied migrations and returns the rolled back steps.
+// Migrations are rolled back in the reverse order of MigrateUp.
+func (db *Database) MigrateDown(ctx context.Context, count int, opts ...MigrateOption) ([]MigrationStep, error) {
+	return db.migrate(ctx, opts, func(migrations []Migration, applied map[migrationKey]bool) ([]MigrationStep, error) {
+		steps := []MigrationStep{}
+		for _, migr := range slices.Backward(migrations) {
+			if len(steps) == count {
+				break
+			}
+			if applied[migr.key()] {
+				steps = append(steps, migr.step(MigrationDown))
+			}
+		}
+
+		return steps, nil
+	})
+}
+
+// MigrateTo applies or rolls back migrations so that the target migration is the latest applied one.
+// Target is either "repository/id" or a migration ID that is unique among registered repositories.
+func (db *Database) MigrateTo(ctx context.Context, target string, opts ...MigrateOption) ([]MigrationStep, error) {
+	return db.migrate(ctx, opts, func(migrations []Migration, applied map[migrationKey]bool) ([]MigrationStep, error) {
+		targetIndex, err := findMigration(migrations, target)
+		if err != nil {
+			return nil, err
+		}
+
+		steps := []MigrationStep{}
+		for i, migr := range slices.Backward(migrations) {
+			if i > targetIndex && applied[migr.key()] {
+				steps = append(steps, migr.step(MigrationDown))
+			}
+		}
+		for _, migr := range migrations[:targetIndex+1] {
+			if !applied[migr.key()] {
+				steps = append(steps, migr.step(MigrationUp))
+			}
+		}
+
+		return steps, nil
+	})
+}
+
+// MigrateRedo rolls back the latest applied migration and applies it again.
+// It returns ErrNoAppliedMigrations if no migration is applied.
+func (db *Database) MigrateRedo(ctx context.Context, opts ...MigrateOption) ([]MigrationStep, error) {
+	return db.migrate(ctx, opts, func(migrations []Migration, applied map[migrationKey]bool) ([]MigrationStep, error) {
+		for _, migr := range slices.Backward(migrations) {
+			if applied[migr.key()] {
+				return []MigrationStep{migr.step(MigrationDown), migr.step(MigrationUp)}, nil
+			}
+		}
+
+		return nil, ErrNoAppliedMigrations
+	})
+}
+
+// migrate takes the migration lock, plans steps with the current migration state and runs them.
+func (db *Database) migrate(ctx context.Context, opts []MigrateOption, plan func([]Migration, map[migrationKey]bool) ([]MigrationStep, error)) ([]MigrationStep, error) {
+	config := migrateConfig{lockTimeout: DefaultMigrationLockTimeout}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	// Migrations are ordered before taking the lock to fail fast on invalid dependencies
+	_, err := db.orderedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	locked, unlock, err := db.service.lockMigrations(ctx, config.lockTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	migrations, applied, err := db.migrationState(ctx, locked, config)
+	if err != nil {
+		return nil, err
+	}
+
+	steps, err := plan(migrations, applied)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.runMigrationSteps(ctx, locked, migrations, steps, config)
+}
+
+// migrationState returns migrations of all registered repositories in order and the set of applied ones.
+// It fails if applied migrations don't match registered ones, unless drift is allowed.
+func (db *Database) migrationState(ctx context.Context, svc *service, config migrateConfig) ([]Migration, map[migrationKey]bool, error) {
+	migrations, err := db.orderedMigrations()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Ensure that migration table exists, unless nothing may be changed
+	if !config.dryRun {
+		err = svc.migrateSelf(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	migrationLogs, err := svc.appliedMigrationLogs(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to select migrations state: %w", err)
+	}
+
+	err = checkDrift(ctx, migrations, migrationLogs, config.allowDrift)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	applied := make(map[migrationKey]bool, len(migrationLogs))
+	for _, l := range migrationLogs {
+		applied[migrationKey{repository: l.Repository, id: l.MigrationID}] = true
+	}
+
+	return migrations, applied, nil
+}
+
+// orderedMigrations returns migrations of all registered repositories in the order they are applied.
+func (db *Database) orderedMigrations() ([]Migration, error) {
+	order, err := db.repositoryMigrationOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := []Migration{}
+	for _, name := range order {
+		repositoryMigrations := db.migrators[name].Migrations()
+		for i := range repositoryMigrations {
+			repositoryMigrations[i].repository = name
+		}
+
+		repositoryMigrations, err := selectMigrations(repositoryMigrations, db.dialect.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		for _, migr := range repositoryMigrations {
+			err := migr.validate()
+			if err != nil {
+				return nil, err
+			}
+			migrations = append(migrations, migr)
+		}
+	}
+
+	return migrations, nil
+}
+
+// repositoryMigrationOrder returns names of repositories with migrations in registration order,
+// moving every repository after the repositories it depends on.
+func (db *Database) repositoryMigrationOrder() ([]string, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[string]int, len(db.registrationOrder))
+	order := make([]string, 0, len(db.migrators))
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("%w: %s", ErrRepositoryDependencyCycle, strings.Join(append(path, name), " -> "))
+		}
+
+		state[name] = visiting
+		for _, dep := range db.repositoryConfigs[name].dependsOn {
+			if _, ok := db.repositories[dep]; !ok {
+				return fmt.Errorf("%w: repository %q depends on %q", ErrUnknownRepositoryDependency, name, dep)
+			}
+
+			err := visit(dep, append(path, name))
+			if err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+
+		if _, ok := db.migrators[name]; ok {
+			order = append(order, name)
+		}
+
+		return nil
+	}
+
+	for _, name := range db.registrationOrder {
+		err := visit(name, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
+
+// findMigration returns the index of the target migration, see MigrateTo for the target format.
+func findMigration(migrations []Migration, target string) (int, error) {
+	repository, id, hasRepository := strings.Cut(target, "/")
+	if !hasRepository {
+		repository, id = "", target
+	}
+
+	found := -1
+	for i, migr := range migrations {
+		if migr.ID != id || (hasRepository && migr.repository != repository) {
+			continue
+		}
+
+		if found >= 0 {
+			return 0, fmt.Errorf("%w: %s exists in %s and %s, use repository/id", ErrAmbiguousMigration, id, migrations[found].repository, migr.repository)
+		}
+		found = i
+	}
+
+	if found < 0 {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownMigration, target)
+	}
+
+	return found, nil
+}
+
+// runMigrationSteps runs the steps one by one, recording every step in the migration log as it succeeds.
+// If a step fails, steps already run by this call are undone in reverse order.
+func (db *Database) runMigrationSteps(ctx context.Context, svc *service, migrations []Migration, steps []MigrationStep, config migrateConfig) ([]MigrationStep, error) {
+	byKey := make(map[migrationKey]Migration, len(migrations))
+	for _, migr := range migrations {
+		byKey[migr.key()] = migr
+	}
+
+	for _, step := range steps {
+		if step.Direction == MigrationDown && !byKey[step.key()].reversible() {
+			return nil, fmt.Errorf("%w: %s/%s", ErrIrreversibleMigration, step.Repository, step.ID)
+		}
+	}
+
+	if config.dryRun {
+		return steps, nil
+	}
+
+	done := []MigrationStep{}
+	for _, step := range steps {
+		err := svc.runMigrationStep(ctx, byKey[step.key()], step.Direction)
+		if err != nil {
+			undoErr := db.undoMigrationSteps(ctx, svc, byKey, done)
+			if undoErr != nil {
+				log.ErrorContext(ctx, "got error(s) trying to undo migrations", "error", undoErr)
+			}
+			return nil, err
+		}
+
+		done = append(done, step)
+	}
+
+	return done, nil
+}
+
+// undoMigrationSteps runs steps in reverse order and in the opposite direction.
+func (db *Database) undoMigrationSteps(ctx context.Context, svc *service, byKey map[migrationKey]Migration, steps []MigrationStep) error {
+	masterErr := error(nil)
+	for _, step := range slices.Backward(steps) {
+		direction := MigrationDown
+		if step.Direction == MigrationDown {
+			direction = MigrationUp
+		}
+
+		err := svc.runMigrationStep(ctx, byKey[step.key()], direction)
+		if err != nil {
+			masterErr = errors.Join(masterErr, err)
+		}
+	}
+
+	return masterErr
+}
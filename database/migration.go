@@ -1,23 +1,120 @@
 package database
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
 	"time"
+
+	"github.com/jmoiron/sqlx"
 )
 
+// ErrInvalidMigration is returned when a registered migration is malformed, e.g. has no Up statement.
+var ErrInvalidMigration = errors.New("invalid migration")
+
 type migrationLog struct {
-	Repository  string    `db:"repository"`
-	MigrationID string    `db:"id"`
-	Timestamp   time.Time `db:"timestamp"`
+	Repository  string         `db:"repository"`
+	MigrationID string         `db:"id"`
+	Timestamp   time.Time      `db:"timestamp"`
+	Checksum    sql.NullString `db:"checksum"` // Empty for migrations applied before checksums were stored
 }
 
+// MigrationFunc is a migration written in Go, e.g. a data backfill. It runs in the migration transaction.
+type MigrationFunc func(ctx context.Context, tx *sqlx.Tx) error
+
 // Migration represents a database migration with up and down SQL statements.
+// Instead of SQL, a migration can be a Go function set with UpFunc and DownFunc.
+//
+// A migration applies to all dialects unless Dialects lists the names of dialects it is written for.
+// A repository can return several migrations with the same ID for different dialects, then a migration
+// for the dialect of the database is used over the one for all dialects.
+//
+// A migration runs in a transaction together with the update of the migration log, so it is either
+// applied and recorded or not applied at all. Set NoTransaction for statements that can't run
+// in a transaction, e.g. CREATE INDEX CONCURRENTLY. Such a migration should consist of a single
+// statement, as a failure leaves the statements before it applied.
 type Migration struct {
-	ID         string
-	Up         string
-	Down       string
-	repository string
+	ID            string
+	Up            string
+	Down          string
+	UpFunc        MigrationFunc
+	DownFunc      MigrationFunc
+	NoTransaction bool
+	Dialects      []string
+	repository    string
+}
+
+// validate checks that the migration has either SQL or Go function for each direction.
+func (m Migration) validate() error {
+	switch {
+	case m.ID == "":
+		return fmt.Errorf("%w: migration without ID", ErrInvalidMigration)
+	case m.Up == "" && m.UpFunc == nil:
+		return fmt.Errorf("%w: %s/%s has neither Up nor UpFunc", ErrInvalidMigration, m.repository, m.ID)
+	case m.Up != "" && m.UpFunc != nil, m.Down != "" && m.DownFunc != nil:
+		return fmt.Errorf("%w: %s/%s has both SQL and Go function", ErrInvalidMigration, m.repository, m.ID)
+	case m.NoTransaction && (m.UpFunc != nil || m.DownFunc != nil):
+		return fmt.Errorf("%w: %s/%s is a Go function and can't run outside of transaction", ErrInvalidMigration, m.repository, m.ID)
+	}
+
+	return nil
+}
+
+// selectMigrations returns migrations for the dialect in order of first appearance of their IDs.
+// It fails if there are several migrations with the same ID for the dialect or for all dialects.
+func selectMigrations(migrations []Migration, dialect string) ([]Migration, error) {
+	selected := []Migration{}
+	indexes := map[string]int{}
+	dialectSpecific := map[string]bool{}
+
+	for _, migr := range migrations {
+		specific := len(migr.Dialects) > 0
+		if specific && !slices.Contains(migr.Dialects, dialect) {
+			continue
+		}
+
+		i, ok := indexes[migr.ID]
+		switch {
+		case !ok:
+			indexes[migr.ID] = len(selected)
+			selected = append(selected, migr)
+			dialectSpecific[migr.ID] = specific
+		case specific == dialectSpecific[migr.ID]:
+			return nil, fmt.Errorf("%w: %s/%s is defined more than once", ErrInvalidMigration, migr.repository, migr.ID)
+		case specific:
+			selected[i] = migr
+			dialectSpecific[migr.ID] = true
+		}
+	}
+
+	return selected, nil
+}
+
+// reversible tells whether the migration can be rolled back.
+func (m Migration) reversible() bool {
+	return m.Down != "" || m.DownFunc != nil
 }
 
 type migrator interface {
 	Migrations() []Migration
 }
+
+// migrationKey identifies a migration among all registered repositories.
+type migrationKey struct {
+	repository string
+	id         string
+}
+
+func (m Migration) key() migrationKey {
+	return migrationKey{repository: m.repository, id: m.ID}
+}
+
+func (m Migration) step(direction MigrationDirection) MigrationStep {
+	return MigrationStep{Repository: m.repository, ID: m.ID, Direction: direction}
+}
+
+func (s MigrationStep) key() migrationKey {
+	return migrationKey{repository: s.Repository, id: s.ID}
+}
@@ -0,0 +1,137 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/platforma-dev/platforma/log"
+)
+
+// DefaultConnectBackoff is the default delay before the first connect retry, see WithConnectRetry.
+const DefaultConnectBackoff = 500 * time.Millisecond
+
+// maxConnectBackoff caps the delay between connect retries.
+const maxConnectBackoff = 30 * time.Second
+
+// Option configures a Database created with New.
+type Option func(*config)
+
+type config struct {
+	dialect        Dialect
+	pool           []func(*sqlx.DB) // Pool settings applied to the primary and replica connections
+	connectTimeout time.Duration
+	connectRetries int
+	connectBackoff time.Duration
+	replicas       []string
+}
+
+// WithDialect sets the dialect of the database, Postgres by default.
+// The driver of the dialect has to be imported by the application.
+func WithDialect(dialect Dialect) Option {
+	return func(c *config) {
+		c.dialect = dialect
+	}
+}
+
+// WithMaxOpenConns sets the maximum number of open connections, see sql.DB.SetMaxOpenConns.
+func WithMaxOpenConns(n int) Option {
+	return func(c *config) {
+		c.pool = append(c.pool, func(db *sqlx.DB) { db.SetMaxOpenConns(n) })
+	}
+}
+
+// WithMaxIdleConns sets the maximum number of idle connections, see sql.DB.SetMaxIdleConns.
+func WithMaxIdleConns(n int) Option {
+	return func(c *config) {
+		c.pool = append(c.pool, func(db *sqlx.DB) { db.SetMaxIdleConns(n) })
+	}
+}
+
+// WithConnMaxLifetime sets how long a connection may be reused, see sql.DB.SetConnMaxLifetime.
+func WithConnMaxLifetime(d time.Duration) Option {
+	return func(c *config) {
+		c.pool = append(c.pool, func(db *sqlx.DB) { db.SetConnMaxLifetime(d) })
+	}
+}
+
+// WithConnMaxIdleTime sets how long a connection may be idle before it is closed, see sql.DB.SetConnMaxIdleTime.
+func WithConnMaxIdleTime(d time.Duration) Option {
+	return func(c *config) {
+		c.pool = append(c.pool, func(db *sqlx.DB) { db.SetConnMaxIdleTime(d) })
+	}
+}
+
+// WithConnectTimeout limits every attempt to connect to the database in New. There is no limit by default.
+func WithConnectTimeout(d time.Duration) Option {
+	return func(c *config) {
+		c.connectTimeout = d
+	}
+}
+
+// WithConnectRetry makes New retry connecting up to the given number of times, e.g. while the database
+// is still starting. The backoff is the delay before the first retry, doubled for every next one.
+// DefaultConnectBackoff is used if the backoff is not positive.
+func WithConnectRetry(retries int, backoff time.Duration) Option {
+	return func(c *config) {
+		c.connectRetries = retries
+		c.connectBackoff = backoff
+	}
+}
+
+// WithReplicas adds read replicas of the database. They use the dialect and pool settings of the database.
+// Reads are routed to replicas with UseReplica.
+func WithReplicas(connections ...string) Option {
+	return func(c *config) {
+		c.replicas = append(c.replicas, connections...)
+	}
+}
+
+// connect opens a connection pool and pings the database, retrying as configured.
+func connect(connection string, config config) (*sqlx.DB, error) {
+	db, err := sqlx.Open(config.dialect.DriverName(), connection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open connection: %w", err)
+	}
+
+	for _, set := range config.pool {
+		set(db)
+	}
+
+	backoff := config.connectBackoff
+	if backoff <= 0 {
+		backoff = DefaultConnectBackoff
+	}
+
+	for attempt := 0; ; attempt++ {
+		err = ping(db, config.connectTimeout)
+		if err == nil {
+			return db, nil
+		}
+
+		if attempt >= config.connectRetries {
+			_ = db.Close()
+			return nil, err
+		}
+
+		log.Warn("failed to connect to database, retrying", "error", err, "attempt", attempt+1, "backoff", backoff)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxConnectBackoff)
+	}
+}
+
+func ping(db *sqlx.DB, timeout time.Duration) error {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	err := db.PingContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package database_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/platforma-dev/platforma/database"
+)
+
+func TestNewOptions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("pool settings", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		db, err := database.New(
+			filepath.Join(dir, "primary.db"),
+			database.WithDialect(database.SQLite{}),
+			database.WithMaxOpenConns(3),
+			database.WithReplicas(filepath.Join(dir, "replica.db")),
+		)
+		if err != nil {
+			t.Fatalf("failed to initialize database: %s", err.Error())
+		}
+		t.Cleanup(func() { _ = db.Close() })
+
+		if db.Connection().Stats().MaxOpenConnections != 3 {
+			t.Fatalf("expected 3 max open connections, got: %d", db.Connection().Stats().MaxOpenConnections)
+		}
+
+		replicas := db.Replicas()
+		if len(replicas) != 1 || replicas[0].Stats().MaxOpenConnections != 3 {
+			t.Fatalf("expected replica with pool settings of primary, got: %v", replicas)
+		}
+	})
+
+	t.Run("connect retry", func(t *testing.T) {
+		t.Parallel()
+
+		// SQLite fails to open a database in a missing directory
+		startedAt := time.Now()
+		_, err := database.New(
+			filepath.Join(t.TempDir(), "missing", "test.db"),
+			database.WithDialect(database.SQLite{}),
+			database.WithConnectRetry(2, 10*time.Millisecond),
+		)
+		if err == nil {
+			t.Fatalf("expected connect error")
+		}
+
+		// 10ms before the first retry and 20ms before the second one
+		if time.Since(startedAt) < 30*time.Millisecond {
+			t.Fatalf("expected connect to be retried with backoff, took: %s", time.Since(startedAt))
+		}
+	})
+
+	t.Run("connect retry without backoff", func(t *testing.T) {
+		t.Parallel()
+
+		startedAt := time.Now()
+		_, err := database.New(
+			filepath.Join(t.TempDir(), "missing", "test.db"),
+			database.WithDialect(database.SQLite{}),
+			database.WithConnectRetry(1, 0),
+		)
+		if err == nil {
+			t.Fatalf("expected connect error")
+		}
+
+		if time.Since(startedAt) < database.DefaultConnectBackoff {
+			t.Fatalf("expected connect to be retried with default backoff, took: %s", time.Since(startedAt))
+		}
+	})
+
+	t.Run("read from replica", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		for _, name := range []string{"primary", "replica"} {
+			db, err := database.New(filepath.Join(dir, name+".db"), database.WithDialect(database.SQLite{}))
+			if err != nil {
+				t.Fatalf("failed to initialize database: %s", err.Error())
+			}
+
+			_, err = db.ExecContext(ctx, "CREATE TABLE items (id TEXT PRIMARY KEY); INSERT INTO items (id) VALUES ($1)", name)
+			if err != nil {
+				t.Fatalf("failed to create items: %s", err.Error())
+			}
+			_ = db.Close()
+		}
+
+		db, err := database.New(
+			filepath.Join(dir, "primary.db"),
+			database.WithDialect(database.SQLite{}),
+			database.WithReplicas(filepath.Join(dir, "replica.db")),
+		)
+		if err != nil {
+			t.Fatalf("failed to initialize database: %s", err.Error())
+		}
+		t.Cleanup(func() { _ = db.Close() })
+
+		getItem := func(ctx context.Context) string {
+			t.Helper()
+
+			var id string
+			err := db.GetContext(ctx, &id, "SELECT id FROM items")
+			if err != nil {
+				t.Fatalf("failed to get item: %s", err.Error())
+			}
+			return id
+		}
+
+		if id := getItem(ctx); id != "primary" {
+			t.Fatalf("expected read from primary, got: %s", id)
+		}
+
+		if id := getItem(database.UseReplica(ctx)); id != "replica" {
+			t.Fatalf("expected read from replica, got: %s", id)
+		}
+
+		err = db.WithTx(ctx, func(ctx context.Context) error {
+			if id := getItem(database.UseReplica(ctx)); id != "primary" {
+				t.Fatalf("expected read in transaction from primary, got: %s", id)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+	})
+}
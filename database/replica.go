@@ -0,0 +1,33 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// replicaKey is the context key that marks reads to be routed to replicas.
+type replicaKey struct{}
+
+// UseReplica returns a context that routes reads run through the database with it (GetContext, SelectContext,
+// QueryxContext and QueryRowxContext) to read replicas, picked round robin. Reads in a transaction started
+// by WithTx and all writes still go to the primary, as do reads of a database without replicas.
+//
+// Replicas may lag behind the primary, so use it only for reads that tolerate slightly stale data.
+func UseReplica(ctx context.Context) context.Context {
+	return context.WithValue(ctx, replicaKey{}, true)
+}
+
+// reader returns a replica if the context routes reads to replicas, and the queryer of the context otherwise.
+func (db *Database) reader(ctx context.Context) sqlx.ExtContext {
+	if len(db.replicas) == 0 || ctx.Value(replicaKey{}) == nil {
+		return db.queryer(ctx)
+	}
+
+	if _, ok := ctx.Value(txKey{db: db}).(*txState); ok {
+		return db.queryer(ctx)
+	}
+
+	i := (db.nextReplica.Add(1) - 1) % uint64(len(db.replicas))
+	return db.replicas[i]
+}
@@ -2,30 +2,194 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 )
 
+var (
+	errMigrationLockInTransaction = errors.New("migration lock can't be taken in a transaction")
+	errNoTransaction              = errors.New("migration function must run in a transaction")
+)
+
 type repository struct {
-	db *sqlx.DB
+	db      sqlx.ExtContext // Either the connection pool, a single connection or the transaction the repository runs in
+	dialect Dialect
+}
+
+// beginner is implemented by the connection pool and single connections, but not by transactions.
+type beginner interface {
+	BeginTxx(ctx context.Context, opts *sql.TxOptions) (*sqlx.Tx, error)
+}
+
+// lockedConn is the connection holding the migration lock. It adds methods of sqlx.ExtContext missing in sqlx.Conn.
+type lockedConn struct {
+	*sqlx.Conn
+	driverName string
+}
+
+func (c lockedConn) DriverName() string {
+	return c.driverName
 }
 
-func newRepository(db *sqlx.DB) *repository {
-	return &repository{db: db}
+func (c lockedConn) BindNamed(query string, arg any) (string, []any, error) {
+	return sqlx.BindNamed(sqlx.BindType(c.driverName), query, arg) //nolint:wrapcheck // Errors are returned as is like by sqlx
 }
 
+func newRepository(db *sqlx.DB, dialect Dialect) *repository {
+	return &repository{db: db, dialect: dialect}
+}
+
+// inTransaction calls fn with a repository that runs in a transaction. The transaction is committed
+// if fn succeeds and rolled back otherwise. If the repository already runs in a transaction, fn reuses it.
+func (r *repository) inTransaction(ctx context.Context, fn func(*repository) error) error {
+	db, ok := r.db.(beginner)
+	if !ok {
+		return fn(r)
+	}
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	err = fn(&repository{db: tx, dialect: r.dialect})
+	if err != nil {
+		rollbackErr := tx.Rollback()
+		if rollbackErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to roll back transaction: %w", rollbackErr))
+		}
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// migrationLockID is the key of the PostgreSQL advisory lock held while migrating, see Postgres.MigrationLockQueries.
+const migrationLockID int64 = 0x706c6174666f726d // "platform"
+
+// migrationLockPollInterval is how often the migration lock is retried while another instance holds it.
+const migrationLockPollInterval = 100 * time.Millisecond
+
+// selfRepository is the repository name migrations of the migration log table are logged under.
+const selfRepository = "platforma_migration"
+
+// migrations returns migrations of the migration log table. PostgreSQL tables created by
+// older versions get the checksum column and the primary key with separate migrations,
+// while tables of other dialects are created complete.
 func (r *repository) migrations() []Migration {
 	return []Migration{{
-		ID:   "init",
-		Up:   "CREATE TABLE IF NOT EXISTS platforma_migrations (repository TEXT, id TEXT, timestamp TIMESTAMP)",
-		Down: "DROP TABLE platforma_migrations",
+		ID: "init",
+		// New tables get the checksum column right away, as the log of this migration is saved with it.
+		// Older tables get it with the checksum migration, which goes before other migrations for the same reason.
+		Up:       "CREATE TABLE IF NOT EXISTS platforma_migrations (repository TEXT, id TEXT, timestamp TIMESTAMP, checksum TEXT)",
+		Down:     "DROP TABLE platforma_migrations",
+		Dialects: []string{DialectPostgres},
+	}, {
+		ID:       "checksum",
+		Up:       "ALTER TABLE platforma_migrations ADD COLUMN IF NOT EXISTS checksum TEXT",
+		Down:     "ALTER TABLE platforma_migrations DROP COLUMN checksum",
+		Dialects: []string{DialectPostgres},
+	}, {
+		ID: "primary_key",
+		Up: `
+			DELETE FROM platforma_migrations a USING platforma_migrations b
+			WHERE a.repository = b.repository AND a.id = b.id AND a.ctid > b.ctid;
+			ALTER TABLE platforma_migrations ADD PRIMARY KEY (repository, id)
+		`,
+		Down:     "ALTER TABLE platforma_migrations DROP CONSTRAINT platforma_migrations_pkey",
+		Dialects: []string{DialectPostgres},
+	}, {
+		ID: "init",
+		Up: `CREATE TABLE IF NOT EXISTS platforma_migrations (
+			repository VARCHAR(255) NOT NULL, id VARCHAR(255) NOT NULL, timestamp DATETIME(6), checksum VARCHAR(64),
+			PRIMARY KEY (repository, id)
+		)`,
+		Down:     "DROP TABLE platforma_migrations",
+		Dialects: []string{DialectMySQL},
+	}, {
+		ID: "init",
+		Up: `CREATE TABLE IF NOT EXISTS platforma_migrations (
+			repository TEXT NOT NULL, id TEXT NOT NULL, timestamp TIMESTAMP, checksum TEXT,
+			PRIMARY KEY (repository, id)
+		)`,
+		Down:     "DROP TABLE platforma_migrations",
+		Dialects: []string{DialectSQLite},
 	}}
 }
 
+// lockMigrations takes the migration lock of the dialect on a dedicated connection, waiting up to the timeout
+// while another session holds it. It returns a repository running on the locked connection, so migrating
+// doesn't need other connections of the pool, and the function that releases the lock.
+func (r *repository) lockMigrations(ctx context.Context, timeout time.Duration) (*repository, func(context.Context) error, error) {
+	db, ok := r.db.(*sqlx.DB)
+	if !ok {
+		return nil, nil, errMigrationLockInTransaction
+	}
+
+	lockQuery, unlockQuery := r.dialect.MigrationLockQueries()
+	if lockQuery == "" {
+		return r, func(context.Context) error { return nil }, nil
+	}
+
+	conn, err := db.Connx(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+
+	lockCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		var locked bool
+		err := conn.QueryRowxContext(lockCtx, lockQuery).Scan(&locked)
+		if err == nil && locked {
+			break
+		}
+
+		if err == nil {
+			select {
+			case <-lockCtx.Done():
+				err = lockCtx.Err()
+			case <-time.After(migrationLockPollInterval):
+				continue
+			}
+		}
+
+		_ = conn.Close()
+		if lockCtx.Err() != nil && ctx.Err() == nil {
+			return nil, nil, ErrMigrationLockTimeout
+		}
+		return nil, nil, fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+
+	locked := &repository{db: lockedConn{Conn: conn, driverName: db.DriverName()}, dialect: r.dialect}
+
+	return locked, func(ctx context.Context) error {
+		defer conn.Close()
+
+		_, err := conn.ExecContext(ctx, unlockQuery)
+		if err != nil {
+			// Discard the connection, so the session holding the lock ends instead of returning to the pool
+			_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+			return fmt.Errorf("failed to release migration lock: %w", err)
+		}
+		return nil
+	}, nil
+}
+
 func (r *repository) getMigrationLogs(ctx context.Context) ([]migrationLog, error) {
 	var migrations []migrationLog
-	err := r.db.SelectContext(ctx, &migrations, "SELECT * FROM platforma_migrations")
+	err := sqlx.SelectContext(ctx, r.db, &migrations, "SELECT * FROM platforma_migrations")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get migration logs: %w", err)
 	}
@@ -33,18 +197,49 @@ func (r *repository) getMigrationLogs(ctx context.Context) ([]migrationLog, erro
 	return migrations, nil
 }
 
+// migrationLogExists tells whether the migration log table exists. It fails for dialects it can't check.
+func (r *repository) migrationLogExists(ctx context.Context) (bool, error) {
+	var query string
+	switch r.dialect.Name() {
+	case DialectPostgres:
+		query = "SELECT to_regclass('platforma_migrations') IS NOT NULL"
+	case DialectMySQL:
+		query = "SELECT COUNT(*) > 0 FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = 'platforma_migrations'"
+	case DialectSQLite:
+		query = "SELECT COUNT(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = 'platforma_migrations'"
+	default:
+		return false, fmt.Errorf("can't check migration log table of %s dialect", r.dialect.Name())
+	}
+
+	var exists bool
+	err := sqlx.GetContext(ctx, r.db, &exists, query)
+	if err != nil {
+		return false, fmt.Errorf("failed to check migration log table: %w", err)
+	}
+	return exists, nil
+}
+
 func (r *repository) saveMigrationLog(ctx context.Context, log migrationLog) error {
 	query := `
-		INSERT INTO platforma_migrations (repository, id, timestamp)
-		VALUES (:repository, :id, :timestamp)
+		INSERT INTO platforma_migrations (repository, id, timestamp, checksum)
+		VALUES (:repository, :id, :timestamp, :checksum)
 	`
-	_, err := r.db.NamedExecContext(ctx, query, log)
+	_, err := sqlx.NamedExecContext(ctx, r.db, query, log)
 	if err != nil {
 		return fmt.Errorf("failed to save migration log: %w", err)
 	}
 	return nil
 }
 
+func (r *repository) deleteMigrationLog(ctx context.Context, repository, migrationID string) error {
+	query := r.db.Rebind("DELETE FROM platforma_migrations WHERE repository = ? AND id = ?")
+	_, err := r.db.ExecContext(ctx, query, repository, migrationID)
+	if err != nil {
+		return fmt.Errorf("failed to delete migration log: %w", err)
+	}
+	return nil
+}
+
 func (r *repository) executeQuery(ctx context.Context, query string) error {
 	_, err := r.db.ExecContext(ctx, query)
 	if err != nil {
@@ -52,3 +247,17 @@ func (r *repository) executeQuery(ctx context.Context, query string) error {
 	}
 	return nil
 }
+
+// executeFunc runs the migration function in the transaction the repository runs in.
+func (r *repository) executeFunc(ctx context.Context, fn MigrationFunc) error {
+	tx, ok := r.db.(*sqlx.Tx)
+	if !ok {
+		return errNoTransaction
+	}
+
+	err := fn(ctx, tx)
+	if err != nil {
+		return fmt.Errorf("failed to execute migration function: %w", err)
+	}
+	return nil
+}
@@ -2,7 +2,7 @@ package database
 
 import (
 	"context"
-	"errors"
+	"database/sql"
 	"fmt"
 	"slices"
 	"time"
@@ -26,110 +26,131 @@ func (s *service) getMigrationLogs(ctx context.Context) ([]migrationLog, error)
 	return logs, nil
 }
 
-func (s *service) saveMigrationLog(ctx context.Context, repository, migrationID string) error {
-	err := s.repo.saveMigrationLog(ctx, migrationLog{Repository: repository, MigrationID: migrationID, Timestamp: time.Now()})
-	if err != nil {
-		return fmt.Errorf("failed to save migration log: %w", err)
+// appliedMigrationLogs returns migration logs without creating the migration log table.
+// Before the first migration the table doesn't exist and no migrations are applied.
+func (s *service) appliedMigrationLogs(ctx context.Context) ([]migrationLog, error) {
+	logs, err := s.getMigrationLogs(ctx)
+	if err == nil {
+		return logs, nil
 	}
-	return nil
+
+	exists, existsErr := s.repo.migrationLogExists(ctx)
+	if existsErr != nil || exists {
+		return nil, err
+	}
+	return nil, nil
 }
 
-func (s *service) saveMigrationLogs(ctx context.Context, migrations []Migration) error {
-	masterErr := error(nil)
-	for _, migr := range migrations {
-		err := s.saveMigrationLog(ctx, migr.repository, migr.ID)
-		if err != nil {
-			masterErr = errors.Join(masterErr, err)
-		}
+// lockMigrations takes the migration lock and returns the service running on the locked connection,
+// which has to be used for migrating while the lock is held, and the function that releases the lock.
+// Releasing errors are logged, as the lock is released anyway when its session ends.
+func (s *service) lockMigrations(ctx context.Context, timeout time.Duration) (*service, func(), error) {
+	log.InfoContext(ctx, "acquiring migration lock")
+
+	repo, unlock, err := s.repo.lockMigrations(ctx, timeout)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return masterErr
+	return newService(repo), func() {
+		err := unlock(context.WithoutCancel(ctx))
+		if err != nil {
+			log.ErrorContext(ctx, "failed to release migration lock", "error", err)
+		}
+	}, nil
 }
 
 func (s *service) migrateSelf(ctx context.Context) error {
-	migrations := s.repo.migrations()
-	appliedMigrations := []Migration{}
 	migrationLogs, err := s.repo.getMigrationLogs(ctx)
-
 	if err != nil {
 		log.InfoContext(ctx, "migrations log table does not exist yet")
 	}
 
+	migrations, err := selectMigrations(s.repo.migrations(), s.repo.dialect.Name())
+	if err != nil {
+		return err
+	}
+
 	for _, migr := range migrations {
+		migr.repository = selfRepository
 		if !slices.ContainsFunc(migrationLogs, func(l migrationLog) bool {
-			return l.Repository == "platforma_migration" && l.MigrationID == migr.ID
+			return l.Repository == migr.repository && l.MigrationID == migr.ID
 		}) {
-			err := s.applyMigration(ctx, migr)
+			err := s.runMigrationStep(ctx, migr, MigrationUp)
 			if err != nil {
-				revertErr := s.revertMigrations(ctx, appliedMigrations)
-				if revertErr != nil {
-					log.ErrorContext(ctx, "got error(s) trying to revert migrations", "error", revertErr)
-				}
 				return err
 			}
-			migr.repository = "platforma_migration"
-			appliedMigrations = append(appliedMigrations, migr)
 		}
 	}
 
-	err = s.saveMigrationLogs(ctx, appliedMigrations)
-	if err != nil {
-		log.ErrorContext(ctx, "got error(s) trying to save migration logs", "error", err.Error())
-	}
-
 	return nil
 }
 
-func (s *service) applyMigration(ctx context.Context, migration Migration) error {
-	err := s.repo.executeQuery(ctx, migration.Up)
+// runMigrationStep applies or rolls back the migration and saves or deletes its migration log.
+// Unless the migration opts out with NoTransaction, both happen in a single transaction.
+func (s *service) runMigrationStep(ctx context.Context, migration Migration, direction MigrationDirection) error {
+	log.InfoContext(ctx, "running migration", "repository", migration.repository, "migration", migration.ID, "direction", direction)
+
+	run := func(repo *repository) error {
+		if direction == MigrationDown {
+			return revertMigration(ctx, repo, migration)
+		}
+		return applyMigration(ctx, repo, migration)
+	}
+
+	var err error
+	if migration.NoTransaction {
+		err = run(s.repo)
+	} else {
+		err = s.repo.inTransaction(ctx, run)
+	}
+
 	if err != nil {
-		return fmt.Errorf("failed to apply migration: %w", err)
+		return fmt.Errorf("migration %s/%s: %w", migration.repository, migration.ID, err)
 	}
+
 	return nil
 }
 
-func (s *service) applyMigrations(ctx context.Context, migrations []Migration, migrationLogs []migrationLog) error {
-	appliedMigrations := []Migration{}
-	for _, migr := range migrations {
-		if !slices.ContainsFunc(migrationLogs, func(l migrationLog) bool {
-			return l.Repository == migr.repository && l.MigrationID == migr.ID
-		}) {
-			err := s.applyMigration(ctx, migr)
-			if err != nil {
-				revertErr := s.revertMigrations(ctx, appliedMigrations)
-				if revertErr != nil {
-					log.ErrorContext(ctx, "got error(s) trying to revert migrations", "error", revertErr)
-				}
-				return err
-			}
-			appliedMigrations = append(appliedMigrations, migr)
-		}
+func applyMigration(ctx context.Context, repo *repository, migration Migration) error {
+	var err error
+	if migration.UpFunc != nil {
+		err = repo.executeFunc(ctx, migration.UpFunc)
+	} else {
+		err = repo.executeQuery(ctx, migration.Up)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply migration: %w", err)
 	}
 
-	err := s.saveMigrationLogs(ctx, appliedMigrations)
+	err = repo.saveMigrationLog(ctx, migrationLog{
+		Repository:  migration.repository,
+		MigrationID: migration.ID,
+		Timestamp:   time.Now(),
+		Checksum:    sql.NullString{String: migration.Checksum(), Valid: migration.Checksum() != ""},
+	})
 	if err != nil {
-		log.ErrorContext(ctx, "got error(s) trying to save migration logs", "error", err.Error())
+		return fmt.Errorf("failed to save migration log: %w", err)
 	}
 
 	return nil
 }
 
-func (s *service) revertMigration(ctx context.Context, migration Migration) error {
-	err := s.repo.executeQuery(ctx, migration.Down)
+func revertMigration(ctx context.Context, repo *repository, migration Migration) error {
+	var err error
+	if migration.DownFunc != nil {
+		err = repo.executeFunc(ctx, migration.DownFunc)
+	} else {
+		err = repo.executeQuery(ctx, migration.Down)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to revert migration: %w", err)
 	}
-	return nil
-}
 
-func (s *service) revertMigrations(ctx context.Context, migrations []Migration) error {
-	masterErr := error(nil)
-	for _, migr := range slices.Backward(migrations) {
-		err := s.revertMigration(ctx, migr)
-		if err != nil {
-			masterErr = errors.Join(masterErr, fmt.Errorf("failed to revert migration %s: %w", migr.ID, err))
-		}
+	err = repo.deleteMigrationLog(ctx, migration.repository, migration.ID)
+	if err != nil {
+		return fmt.Errorf("failed to delete migration log: %w", err)
 	}
 
-	return masterErr
+	return nil
 }
@@ -0,0 +1,92 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidMigrationFile is returned by LoadMigrations for SQL files that don't follow the naming scheme.
+var ErrInvalidMigrationFile = errors.New("invalid migration file")
+
+// migrationFileName matches migration files named NNN_name.up.sql and NNN_name.down.sql.
+var migrationFileName = regexp.MustCompile(`^(([0-9]+)_[A-Za-z0-9_-]+)\.(up|down)\.sql$`)
+
+// LoadMigrations reads migrations from SQL files in the directory of fsys, e.g. an embed.FS.
+// Files are named NNN_name.up.sql and NNN_name.down.sql, where NNN is the version the migrations
+// are ordered by and NNN_name is the migration ID. The down file is optional. Files without the .sql
+// extension are ignored.
+func LoadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	versions := map[int]string{}
+	migrations := map[string]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("%w: %s doesn't match NNN_name.up.sql or NNN_name.down.sql", ErrInvalidMigrationFile, entry.Name())
+		}
+		id, direction := match[1], match[3]
+
+		version, err := strconv.Atoi(match[2])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidMigrationFile, entry.Name(), err)
+		}
+		if other, ok := versions[version]; ok && other != id {
+			return nil, fmt.Errorf("%w: %s and %s have the same version", ErrInvalidMigrationFile, other, id)
+		}
+		versions[version] = id
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration file: %w", err)
+		}
+
+		migr, ok := migrations[id]
+		if !ok {
+			migr = &Migration{ID: id}
+			migrations[id] = migr
+		}
+
+		if direction == "up" {
+			migr.Up = string(content)
+		} else {
+			migr.Down = string(content)
+		}
+	}
+
+	result := make([]Migration, 0, len(migrations))
+	for _, version := range slices.Sorted(maps.Keys(versions)) {
+		migr := migrations[versions[version]]
+		if strings.TrimSpace(migr.Up) == "" {
+			return nil, fmt.Errorf("%w: %s has no up file or it is empty", ErrInvalidMigrationFile, migr.ID)
+		}
+		result = append(result, *migr)
+	}
+
+	return result, nil
+}
+
+// MustLoadMigrations is like LoadMigrations but panics on error.
+// It is intended for embedded migrations, which are known to be valid at build time.
+func MustLoadMigrations(fsys fs.FS, dir string) []Migration {
+	migrations, err := LoadMigrations(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+
+	return migrations
+}
@@ -0,0 +1,63 @@
+package database_test
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/platforma-dev/platforma/database"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ordered by version", func(t *testing.T) {
+		t.Parallel()
+
+		fsys := fstest.MapFS{
+			"migrations/010_add_email.up.sql":      {Data: []byte("ALTER TABLE users ADD COLUMN email TEXT")},
+			"migrations/002_create_users.up.sql":   {Data: []byte("CREATE TABLE users (id TEXT)")},
+			"migrations/002_create_users.down.sql": {Data: []byte("DROP TABLE users")},
+			"migrations/README.md":                 {Data: []byte("# Migrations")},
+		}
+
+		migrations, err := database.LoadMigrations(fsys, "migrations")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if len(migrations) != 2 {
+			t.Fatalf("expected 2 migrations, got %d", len(migrations))
+		}
+
+		if migrations[0].ID != "002_create_users" || migrations[0].Up != "CREATE TABLE users (id TEXT)" || migrations[0].Down != "DROP TABLE users" {
+			t.Fatalf("expected create_users migration first, got %+v", migrations[0])
+		}
+
+		if migrations[1].ID != "010_add_email" || migrations[1].Down != "" {
+			t.Fatalf("expected add_email migration without down statement second, got %+v", migrations[1])
+		}
+	})
+
+	t.Run("invalid files", func(t *testing.T) {
+		t.Parallel()
+
+		for name, fsys := range map[string]fstest.MapFS{
+			"invalid name": {
+				"migrations/create_users.up.sql": {Data: []byte("CREATE TABLE users (id TEXT)")},
+			},
+			"same version": {
+				"migrations/001_create_users.up.sql":  {Data: []byte("CREATE TABLE users (id TEXT)")},
+				"migrations/001_create_orders.up.sql": {Data: []byte("CREATE TABLE orders (id TEXT)")},
+			},
+			"missing up file": {
+				"migrations/001_create_users.down.sql": {Data: []byte("DROP TABLE users")},
+			},
+		} {
+			_, err := database.LoadMigrations(fsys, "migrations")
+			if !errors.Is(err, database.ErrInvalidMigrationFile) {
+				t.Fatalf("expected ErrInvalidMigrationFile for %s, got %v", name, err)
+			}
+		}
+	})
+}
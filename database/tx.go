@@ -0,0 +1,203 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// DefaultTxRetries is the default number of times WithTx retries a transaction that failed
+// with a retryable error, see Dialect.IsRetryable.
+const DefaultTxRetries = 3
+
+// txRetryBackoff is the delay before the first retry of a transaction, doubled for every next retry.
+const txRetryBackoff = 10 * time.Millisecond
+
+// txKey is the context key of the transaction started by WithTx of the database.
+type txKey struct {
+	db *Database
+}
+
+// txState is the transaction stored in the context by WithTx.
+type txState struct {
+	tx         *sqlx.Tx
+	savepoints int // Number of savepoints created in the transaction so far
+}
+
+// TxOption configures a transaction started by WithTx.
+type TxOption func(*txConfig)
+
+type txConfig struct {
+	options sql.TxOptions
+	retries int
+}
+
+// TxIsolation sets the isolation level of the transaction, e.g. sql.LevelSerializable.
+func TxIsolation(level sql.IsolationLevel) TxOption {
+	return func(c *txConfig) {
+		c.options.Isolation = level
+	}
+}
+
+// TxReadOnly makes the transaction read only.
+func TxReadOnly() TxOption {
+	return func(c *txConfig) {
+		c.options.ReadOnly = true
+	}
+}
+
+// TxRetries sets how many times the transaction is retried after a retryable error,
+// DefaultTxRetries by default. Zero disables retries.
+func TxRetries(retries int) TxOption {
+	return func(c *txConfig) {
+		c.retries = retries
+	}
+}
+
+// WithTx runs fn in a transaction. The transaction is stored in the context passed to fn and
+// queries run through the database with that context, e.g. by repositories, use it.
+// The transaction is committed if fn returns nil and rolled back if it returns an error or panics.
+//
+// Nested calls with the context of a running transaction create a savepoint instead, which is
+// rolled back if the nested fn fails. Options of nested calls are ignored.
+//
+// Transactions that fail with an error the dialect reports as retryable, e.g. a serialization failure
+// or a deadlock, are retried, so fn has to be safe to run several times.
+func (db *Database) WithTx(ctx context.Context, fn func(ctx context.Context) error, opts ...TxOption) error {
+	if state, ok := ctx.Value(txKey{db: db}).(*txState); ok {
+		return db.withSavepoint(ctx, state, fn)
+	}
+
+	config := txConfig{retries: DefaultTxRetries}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	backoff := txRetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := db.runTx(ctx, config.options, fn)
+		if err == nil || attempt >= config.retries || !db.dialect.IsRetryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(backoff):
+			backoff *= 2
+		}
+	}
+}
+
+// runTx runs fn in a new transaction.
+func (db *Database) runTx(ctx context.Context, options sql.TxOptions, fn func(ctx context.Context) error) (err error) {
+	tx, err := db.conn.BeginTxx(ctx, &options)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	err = fn(context.WithValue(ctx, txKey{db: db}, &txState{tx: tx}))
+	if err != nil {
+		rollbackErr := tx.Rollback()
+		if rollbackErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to roll back transaction: %w", rollbackErr))
+		}
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// withSavepoint runs fn in a savepoint of the running transaction.
+func (db *Database) withSavepoint(ctx context.Context, state *txState, fn func(ctx context.Context) error) (err error) {
+	state.savepoints++
+	savepoint := fmt.Sprintf("platforma_savepoint_%d", state.savepoints)
+
+	_, err = state.tx.ExecContext(ctx, "SAVEPOINT "+savepoint)
+	if err != nil {
+		return fmt.Errorf("failed to create savepoint: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_, _ = state.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+savepoint)
+			panic(p)
+		}
+	}()
+
+	err = fn(ctx)
+	if err != nil {
+		_, rollbackErr := state.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+savepoint)
+		if rollbackErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to roll back to savepoint: %w", rollbackErr))
+		}
+		return err
+	}
+
+	_, err = state.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+savepoint)
+	if err != nil {
+		return fmt.Errorf("failed to release savepoint: %w", err)
+	}
+
+	return nil
+}
+
+// queryer returns the transaction of the context started by WithTx or the connection if there is none.
+func (db *Database) queryer(ctx context.Context) sqlx.ExtContext {
+	if state, ok := ctx.Value(txKey{db: db}).(*txState); ok {
+		return state.tx
+	}
+
+	return db.conn
+}
+
+// ExecContext executes the query in the transaction of the context, if any. See WithTx.
+func (db *Database) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	query, args = rebind(db.dialect.BindType(), query, args)
+	return db.queryer(ctx).ExecContext(ctx, query, args...) //nolint:wrapcheck // Errors are returned as is like by sqlx
+}
+
+// NamedExecContext executes the named query in the transaction of the context, if any. See WithTx.
+func (db *Database) NamedExecContext(ctx context.Context, query string, arg any) (sql.Result, error) {
+	return sqlx.NamedExecContext(ctx, db.queryer(ctx), query, arg) //nolint:wrapcheck // Errors are returned as is like by sqlx
+}
+
+// GetContext gets a single row in the transaction of the context, if any. See WithTx and UseReplica.
+func (db *Database) GetContext(ctx context.Context, dest any, query string, args ...any) error {
+	query, args = rebind(db.dialect.BindType(), query, args)
+	return sqlx.GetContext(ctx, db.reader(ctx), dest, query, args...) //nolint:wrapcheck // Errors are returned as is like by sqlx
+}
+
+// SelectContext selects rows in the transaction of the context, if any. See WithTx and UseReplica.
+func (db *Database) SelectContext(ctx context.Context, dest any, query string, args ...any) error {
+	query, args = rebind(db.dialect.BindType(), query, args)
+	return sqlx.SelectContext(ctx, db.reader(ctx), dest, query, args...) //nolint:wrapcheck // Errors are returned as is like by sqlx
+}
+
+// QueryxContext queries rows in the transaction of the context, if any. See WithTx and UseReplica.
+func (db *Database) QueryxContext(ctx context.Context, query string, args ...any) (*sqlx.Rows, error) {
+	query, args = rebind(db.dialect.BindType(), query, args)
+	return db.reader(ctx).QueryxContext(ctx, query, args...) //nolint:wrapcheck // Errors are returned as is like by sqlx
+}
+
+// QueryRowxContext queries a single row in the transaction of the context, if any. See WithTx and UseReplica.
+func (db *Database) QueryRowxContext(ctx context.Context, query string, args ...any) *sqlx.Row {
+	query, args = rebind(db.dialect.BindType(), query, args)
+	return db.reader(ctx).QueryRowxContext(ctx, query, args...)
+}
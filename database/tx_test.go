@@ -0,0 +1,169 @@
+package database_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/platforma-dev/platforma/database"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+)
+
+func TestWithTx(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ctr, err := postgres.Run(
+		ctx,
+		"postgres:18-alpine",
+		postgres.WithDatabase("hostamat"),
+		postgres.WithUsername("hostamat"),
+		postgres.WithPassword("hostamat"),
+		postgres.BasicWaitStrategies(),
+	)
+	if err != nil {
+		t.Fatalf("failed to initialize database: %s", err.Error())
+	}
+
+	dbURL, err := ctr.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		t.Fatalf("failed to get connection string: %s", err.Error())
+	}
+
+	db, err := database.New(dbURL)
+	if err != nil {
+		t.Fatalf("failed to initialize database: %s", err.Error())
+	}
+
+	db.RegisterRepository("some_repo", simpleRepo{migrations: []database.Migration{{
+		ID:   "init",
+		Up:   "CREATE TABLE items (id TEXT PRIMARY KEY)",
+		Down: "DROP TABLE items",
+	}}})
+
+	err = db.Migrate(ctx)
+	if err != nil {
+		t.Fatalf("failed to migrate database: %s", err.Error())
+	}
+
+	countItems := func(t *testing.T, ctx context.Context) int {
+		t.Helper()
+
+		var count int
+		err := db.GetContext(ctx, &count, "SELECT COUNT(*) FROM items")
+		if err != nil {
+			t.Fatalf("failed to count items: %s", err.Error())
+		}
+		return count
+	}
+
+	t.Run("commit", func(t *testing.T) {
+		err := db.WithTx(ctx, func(ctx context.Context) error {
+			_, err := db.ExecContext(ctx, "INSERT INTO items (id) VALUES ('committed')")
+			if err != nil {
+				return err
+			}
+
+			// Not visible outside of the transaction yet
+			if countItems(t, context.Background()) != 0 {
+				t.Fatalf("expected item to be visible only in transaction")
+			}
+
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+
+		if countItems(t, ctx) != 1 {
+			t.Fatalf("expected item to be committed")
+		}
+
+		_, err = db.ExecContext(ctx, "DELETE FROM items")
+		if err != nil {
+			t.Fatalf("failed to clean items: %s", err.Error())
+		}
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		fnErr := errors.New("something went wrong")
+
+		err := db.WithTx(ctx, func(ctx context.Context) error {
+			_, err := db.ExecContext(ctx, "INSERT INTO items (id) VALUES ('rolled back')")
+			if err != nil {
+				return err
+			}
+			return fnErr
+		})
+		if !errors.Is(err, fnErr) {
+			t.Fatalf("expected error of the function, got: %v", err)
+		}
+
+		if countItems(t, ctx) != 0 {
+			t.Fatalf("expected item to be rolled back")
+		}
+	})
+
+	t.Run("nested savepoint", func(t *testing.T) {
+		err := db.WithTx(ctx, func(ctx context.Context) error {
+			_, err := db.ExecContext(ctx, "INSERT INTO items (id) VALUES ('outer')")
+			if err != nil {
+				return err
+			}
+
+			// Duplicate key fails the nested call, but the outer transaction goes on
+			err = db.WithTx(ctx, func(ctx context.Context) error {
+				_, err := db.ExecContext(ctx, "INSERT INTO items (id) VALUES ('outer')")
+				return err
+			})
+			if err == nil {
+				t.Fatalf("expected nested transaction to fail")
+			}
+
+			return db.WithTx(ctx, func(ctx context.Context) error {
+				_, err := db.ExecContext(ctx, "INSERT INTO items (id) VALUES ('inner')")
+				return err
+			})
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+
+		if countItems(t, ctx) != 2 {
+			t.Fatalf("expected outer and inner items to be committed")
+		}
+
+		_, err = db.ExecContext(ctx, "DELETE FROM items")
+		if err != nil {
+			t.Fatalf("failed to clean items: %s", err.Error())
+		}
+	})
+
+	t.Run("retry serialization failure", func(t *testing.T) {
+		attempts := 0
+		err := db.WithTx(ctx, func(_ context.Context) error {
+			attempts++
+			if attempts < 3 {
+				return &pq.Error{Code: "40001", Message: "could not serialize access"}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+
+		if attempts != 3 {
+			t.Fatalf("expected 3 attempts, got: %d", attempts)
+		}
+
+		attempts = 0
+		err = db.WithTx(ctx, func(_ context.Context) error {
+			attempts++
+			return &pq.Error{Code: "40001", Message: "could not serialize access"}
+		}, database.TxRetries(0))
+		if err == nil || attempts != 1 {
+			t.Fatalf("expected single failed attempt, got %d attempts and error: %v", attempts, err)
+		}
+	})
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/platforma-dev/platforma/admin"
+	"github.com/platforma-dev/platforma/application"
+	"github.com/platforma-dev/platforma/httpserver"
+	"github.com/platforma-dev/platforma/log"
+	"github.com/platforma-dev/platforma/scheduler"
+)
+
+func cleanup(ctx context.Context) error {
+	log.InfoContext(ctx, "cleanup executed")
+	return nil
+}
+
+func main() {
+	ctx := context.Background()
+
+	app := application.New()
+
+	app.OnStartFunc(func(ctx context.Context) error {
+		log.InfoContext(ctx, "cache warmed up")
+		return nil
+	}, application.StartupTaskConfig{Name: "warmup"})
+
+	// Scheduler can be triggered with POST /admin/jobs/cleanup/run
+	app.RegisterService("cleanup", scheduler.New(time.Hour, application.RunnerFunc(cleanup)))
+
+	// Serve admin endpoints on a separate port that is not exposed publicly
+	adminServer := httpserver.New("9090", 3*time.Second)
+	adminServer.HandleGroup("/admin", admin.New(app))
+	app.RegisterService("admin", adminServer)
+
+	if err := app.Run(ctx); err != nil {
+		log.ErrorContext(ctx, "app finished with error", "error", err)
+	}
+
+	// Now you can access http://localhost:9090/admin/services, http://localhost:9090/admin/startup-tasks,
+	// http://localhost:9090/admin/databases and http://localhost:9090/admin/build URLs with GET method
+}
@@ -22,19 +22,20 @@ func main() {
 		return
 	}
 
-	sessionDomain := session.New(db.Connection())
-	authDomain := auth.New(db.Connection(), sessionDomain.Service, "session_id", nil, nil, nil)
+	api := httpserver.New("8080", 3*time.Second)
+	api.Use(httpserver.NewTraceIDMiddleware(nil, ""))
+	api.Use(httpserver.NewRecoverMiddleware())
 
 	app := application.New()
 	app.RegisterDatabase("main", db)
-	app.RegisterDomain("session", "main", sessionDomain)
-	app.RegisterDomain("auth", "main", authDomain)
 
-	api := httpserver.New("8080", 3*time.Second)
-	api.Use(httpserver.NewTraceIDMiddleware(nil, ""))
-	api.Use(httpserver.NewRecoverMiddleware())
+	container := app.Container()
+	application.ProvideValue(container, db)
+	application.Provide(container, session.ProvideService)
+	application.ProvideDomain(app, "session", "main", session.Provide)
+	application.ProvideDomain(app, "auth", "main", auth.Provider("session_id", nil, nil), application.MountOn(api, "/auth"))
 
-	api.HandleGroup("/auth", authDomain.HandleGroup)
+	authDomain := application.MustResolve[*auth.Domain](container)
 
 	protected := httpserver.NewHandlerGroup()
 	protected.Use(authDomain.Middleware)
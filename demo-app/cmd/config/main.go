@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/platforma-dev/platforma/config"
+	"github.com/platforma-dev/platforma/log"
+)
+
+type databaseConfig struct {
+	DSN      string `config:"dsn" default:"postgres://localhost:5432/postgres" required:"true"`
+	Password string `config:"password" secret:"true"`
+}
+
+type appConfig struct {
+	Port     int           `config:"port" default:"8080"`
+	Timeout  time.Duration `config:"timeout" default:"5s"`
+	Database databaseConfig
+}
+
+func main() {
+	ctx := context.Background()
+
+	cfg := config.New[appConfig](
+		config.FromOptionalFile("config.yaml"),
+		config.FromEnv("demo"),
+		config.FromFlags(os.Args[1:]),
+	)
+
+	err := cfg.Load(ctx)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to load config", "error", err)
+		os.Exit(1)
+	}
+
+	log.InfoContext(ctx, "config loaded", "port", cfg.Value().Port, "config", cfg.String())
+}
@@ -28,9 +28,11 @@ export default defineConfig({
             "packages/database",
             "packages/httpserver",
             "packages/log",
+            "packages/config",
             "packages/queue",
             "packages/scheduler",
             "packages/auth",
+            "packages/admin",
           ],
         },
         {
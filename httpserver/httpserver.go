@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/platforma-dev/platforma/application"
 	"github.com/platforma-dev/platforma/log"
 )
 
@@ -59,8 +60,9 @@ func (s *HTTPServer) Run(ctx context.Context) error {
 }
 
 // Healthcheck returns health check information for the HTTP server.
-func (s *HTTPServer) Healthcheck(_ context.Context) any {
-	return map[string]any{
-		"port": s.port,
+func (s *HTTPServer) Healthcheck(_ context.Context) application.HealthCheckResult {
+	return application.HealthCheckResult{
+		Status:  application.HealthStatusHealthy,
+		Details: map[string]any{"port": s.port},
 	}
 }
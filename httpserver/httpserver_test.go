@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/platforma-dev/platforma/application"
 	"github.com/platforma-dev/platforma/httpserver"
 )
 
@@ -86,7 +87,12 @@ func TestHTTPServer(t *testing.T) {
 		t.Parallel()
 
 		server := httpserver.New("8080", 0)
-		hcData, ok := server.Healthcheck(context.TODO()).(map[string]any)
+		result := server.Healthcheck(context.TODO())
+		if result.Status != application.HealthStatusHealthy {
+			t.Fatalf("expected healthy status, got %s", result.Status)
+		}
+
+		hcData, ok := result.Details.(map[string]any)
 		if !ok {
 			t.Fatal("failed type assert health data")
 		}
@@ -9,6 +9,8 @@ func Run(args []string) {
 		versionCommand()
 	case "generate":
 		generateCommand(args[2:])
+	case "migrate":
+		migrateCommand(args[2:])
 	default:
 		log.Error("unknown command", "command", command)
 	}
@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDomainTemplates(t *testing.T) {
+	t.Parallel()
+
+	templates, err := filepath.Glob("templates/domain/*.go.tmpl")
+	if err != nil || len(templates) == 0 {
+		t.Fatalf("expected domain templates, got %v and error: %v", templates, err)
+	}
+
+	data := struct {
+		PackageName string
+		TypeName    string
+	}{PackageName: "order", TypeName: "Order"}
+
+	dir := t.TempDir()
+	for _, templatePath := range templates {
+		file := strings.TrimSuffix(filepath.Base(templatePath), ".tmpl")
+
+		err := writeFromTemplate(dir, file, filepath.ToSlash(templatePath), data)
+		if err != nil {
+			t.Fatalf("failed to render %s: %v", templatePath, err)
+		}
+
+		_, err = parser.ParseFile(token.NewFileSet(), filepath.Join(dir, file), nil, parser.AllErrors)
+		if err != nil {
+			t.Fatalf("expected %s to render valid Go, got: %v", templatePath, err)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"slices"
+
+	"github.com/platforma-dev/platforma/log"
+)
+
+// migrateCommand runs the migrate command of the application in the current project,
+// so that migrations of all its repositories are known. The application must execute
+// commands with application.Application.Main or Execute.
+//
+// The package of the application is set with -app and defaults to the current directory,
+// all other arguments are passed to the application.
+func migrateCommand(args []string) {
+	pkg := "."
+	if len(args) >= 2 && args[0] == "-app" {
+		pkg, args = args[1], args[2:]
+	}
+
+	cmd := exec.Command("go", slices.Concat([]string{"run", pkg, "migrate"}, args)...) //nolint:gosec // Arguments are passed to the application as is
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	err := cmd.Run()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.ExitCode())
+		}
+
+		log.Error("failed to run application", "error", err, "app", pkg)
+		os.Exit(1)
+	}
+}
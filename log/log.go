@@ -40,10 +40,14 @@ const (
 	ServiceNameKey contextKey = "serviceName"
 	// StartupTaskKey is the context key for startup task.
 	StartupTaskKey contextKey = "startupTask"
+	// ShutdownTaskKey is the context key for shutdown task.
+	ShutdownTaskKey contextKey = "shutdownTask"
 	// UserIDKey is the context key for user ID.
 	UserIDKey contextKey = "userId"
 	// WorkerIDKey is the context key worker of queue processor.
 	WorkerIDKey contextKey = "workerId"
+	// CommandKey is the context key for application command.
+	CommandKey contextKey = "command"
 )
 
 type contextHandler struct {
@@ -58,8 +62,10 @@ func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
 		TraceIDKey,
 		ServiceNameKey,
 		StartupTaskKey,
+		ShutdownTaskKey,
 		UserIDKey,
 		WorkerIDKey,
+		CommandKey,
 	}
 
 	for _, key := range defaultKeys {
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"sync"
 	"time"
+
+	"github.com/platforma-dev/platforma/application"
 )
 
 // ErrTimeout is returned when an enqueue operation times out.
@@ -74,3 +76,38 @@ func (q *ChanQueue[T]) EnqueueJob(ctx context.Context, job T) error {
 func (q *ChanQueue[T]) GetJobChan(_ context.Context) (chan T, error) {
 	return q.ch, nil
 }
+
+// ChanQueueStats holds the state of a ChanQueue.
+type ChanQueueStats struct {
+	Open     bool `json:"open"`
+	Depth    int  `json:"depth"`    // Number of jobs waiting in the queue
+	Capacity int  `json:"capacity"` // Maximum number of jobs the queue can hold
+}
+
+// Stats returns the current state of the queue.
+func (q *ChanQueue[T]) Stats() ChanQueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	stats := ChanQueueStats{Open: q.opened, Capacity: q.bufferSize}
+	if q.opened {
+		stats.Depth = len(q.ch)
+	}
+
+	return stats
+}
+
+// Healthcheck reports the queue depth and capacity.
+// The queue is unhealthy until it is opened and degraded when it is full.
+func (q *ChanQueue[T]) Healthcheck(_ context.Context) application.HealthCheckResult {
+	stats := q.Stats()
+
+	switch {
+	case !stats.Open:
+		return application.HealthCheckResult{Status: application.HealthStatusUnhealthy, Message: "queue is not open", Details: stats}
+	case stats.Capacity > 0 && stats.Depth >= stats.Capacity:
+		return application.HealthCheckResult{Status: application.HealthStatusDegraded, Message: "queue is full", Details: stats}
+	default:
+		return application.HealthCheckResult{Status: application.HealthStatusHealthy, Details: stats}
+	}
+}
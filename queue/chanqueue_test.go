@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/platforma-dev/platforma/application"
 	"github.com/platforma-dev/platforma/queue"
 )
 
@@ -97,4 +98,41 @@ func TestChanQueue(t *testing.T) {
 		}
 	})
 
+	t.Run("healthcheck", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		q := queue.NewChanQueue[job](2, time.Second)
+
+		result := q.Healthcheck(ctx)
+		if result.Status != application.HealthStatusUnhealthy {
+			t.Fatalf("expected unopened queue to be unhealthy, got: %s", result.Status)
+		}
+
+		err := q.Open(ctx)
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+		defer q.Close(ctx)
+
+		q.EnqueueJob(ctx, job{data: 1})
+
+		result = q.Healthcheck(ctx)
+		if result.Status != application.HealthStatusHealthy {
+			t.Fatalf("expected queue to be healthy, got: %s", result.Status)
+		}
+
+		stats := q.Stats()
+		if stats.Depth != 1 || stats.Capacity != 2 {
+			t.Fatalf("expected depth 1 and capacity 2, got: %+v", stats)
+		}
+
+		q.EnqueueJob(ctx, job{data: 1})
+
+		result = q.Healthcheck(ctx)
+		if result.Status != application.HealthStatusDegraded {
+			t.Fatalf("expected full queue to be degraded, got: %s", result.Status)
+		}
+	})
+
 }
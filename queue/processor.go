@@ -3,10 +3,13 @@ package queue
 import (
 	"context"
 	"fmt"
+	"runtime/debug"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/platforma-dev/platforma/application"
 	"github.com/platforma-dev/platforma/log"
 )
 
@@ -38,11 +41,75 @@ type Processor[T any] struct {
 	wg              sync.WaitGroup
 	workersAmount   int
 	shutdownTimeout time.Duration
+	ready           chan struct{}
+	markReady       func()
+	busy            atomic.Int64
+	processed       atomic.Int64
+	failed          atomic.Int64
+	lastError       atomic.Pointer[string]
+}
+
+// ProcessorStats holds the state of a Processor.
+type ProcessorStats struct {
+	Workers   int    `json:"workers"`
+	Busy      int64  `json:"busy"`                // Number of workers currently handling a job
+	Processed int64  `json:"processed"`           // Number of successfully handled jobs
+	Failed    int64  `json:"failed"`              // Number of jobs whose handler panicked
+	LastError string `json:"lastError,omitempty"` // Error of the latest failed job
+	Queue     any    `json:"queue,omitempty"`
 }
 
 // New creates a new Processor with the specified handler, queue, and configuration.
 func New[T any](handler Handler[T], queue Provider[T], workersAmount int, shutdownTimeout time.Duration) *Processor[T] {
-	return &Processor[T]{handler: handler, queue: queue, workersAmount: workersAmount, shutdownTimeout: shutdownTimeout}
+	ready := make(chan struct{})
+	return &Processor[T]{
+		handler:         handler,
+		queue:           queue,
+		workersAmount:   workersAmount,
+		shutdownTimeout: shutdownTimeout,
+		ready:           ready,
+		markReady:       sync.OnceFunc(func() { close(ready) }),
+	}
+}
+
+// Ready returns a channel that is closed once the queue is opened and workers are started.
+func (p *Processor[T]) Ready() <-chan struct{} {
+	return p.ready
+}
+
+// Stats returns worker and job counters of the processor.
+func (p *Processor[T]) Stats() ProcessorStats {
+	stats := ProcessorStats{
+		Workers:   p.workersAmount,
+		Busy:      p.busy.Load(),
+		Processed: p.processed.Load(),
+		Failed:    p.failed.Load(),
+	}
+
+	if lastError := p.lastError.Load(); lastError != nil {
+		stats.LastError = *lastError
+	}
+
+	return stats
+}
+
+// Healthcheck reports worker and job counters of the processor.
+// If the queue implements application.Healthchecker, its result is included in details
+// and its status is used as the processor status.
+func (p *Processor[T]) Healthcheck(ctx context.Context) application.HealthCheckResult {
+	stats := p.Stats()
+	result := application.HealthCheckResult{Status: application.HealthStatusHealthy}
+
+	if checker, ok := p.queue.(application.Healthchecker); ok {
+		queueResult := checker.Healthcheck(ctx)
+		stats.Queue = queueResult.Details
+		result.Status = queueResult.Status
+		result.Message = queueResult.Message
+	}
+
+	result.Details = stats
+
+	return result
 }
 
 // Enqueue adds a job to the queue for processing.
@@ -68,6 +135,8 @@ func (p *Processor[T]) Run(ctx context.Context) error {
 		go p.worker(workerCtx)
 	}
 
+	p.markReady()
+
 	p.wg.Wait()
 
 	log.InfoContext(ctx, "all workers shut down")
@@ -83,11 +152,6 @@ func (p *Processor[T]) Run(ctx context.Context) error {
 func (p *Processor[T]) worker(ctx context.Context) {
 	defer p.wg.Done()
 	defer log.InfoContext(ctx, "worker finished")
-	defer func() {
-		if r := recover(); r != nil {
-			log.ErrorContext(ctx, "worker panic recovered", "panic", r)
-		}
-	}()
 
 	log.InfoContext(ctx, "worker started")
 
@@ -109,7 +173,7 @@ func (p *Processor[T]) worker(ctx context.Context) {
 		default:
 			select {
 			case job := <-jobChan:
-				p.handler.Handle(ctx, job)
+				p.handle(ctx, job)
 
 			case <-ctx.Done():
 				log.InfoContext(ctx, "shutting down worker")
@@ -137,7 +201,7 @@ func (p *Processor[T]) worker(ctx context.Context) {
 		default:
 			select {
 			case job := <-jobChan:
-				p.handler.Handle(shutdownCtx, job)
+				p.handle(shutdownCtx, job)
 			case <-shutdownCtx.Done():
 				log.InfoContext(shutdownCtx, "shutdown timeout expired")
 				return
@@ -145,3 +209,24 @@ func (p *Processor[T]) worker(ctx context.Context) {
 		}
 	}
 }
+
+// handle passes the job to the handler and updates job counters.
+// Panics in the handler are recovered as application.ErrServicePanicked so the worker keeps processing jobs.
+func (p *Processor[T]) handle(ctx context.Context, job T) {
+	p.busy.Add(1)
+	defer p.busy.Add(-1)
+
+	defer func() {
+		if r := recover(); r != nil {
+			err := &application.ErrServicePanicked{Value: r, Stack: debug.Stack()}
+			errMessage := err.Error()
+
+			p.failed.Add(1)
+			p.lastError.Store(&errMessage)
+			log.ErrorContext(ctx, "worker panic recovered", "error", err, "stack", string(err.Stack))
+		}
+	}()
+
+	p.handler.Handle(ctx, job)
+	p.processed.Add(1)
+}
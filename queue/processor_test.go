@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/platforma-dev/platforma/application"
 	"github.com/platforma-dev/platforma/queue"
 )
 
@@ -111,6 +112,52 @@ func TestProcessor(t *testing.T) {
 			}
 		})
 	})
+
+	t.Run("healthcheck", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		q := queue.NewChanQueue[job](10, time.Second)
+		p := queue.New(queue.HandlerFunc[job](func(_ context.Context, job job) {
+			if job.data < 0 {
+				panic("negative job")
+			}
+		}), q, 2, time.Microsecond)
+
+		go p.Run(ctx)
+		<-p.Ready()
+
+		p.Enqueue(ctx, job{data: 1})
+		p.Enqueue(ctx, job{data: -1})
+		p.Enqueue(ctx, job{data: 1})
+
+		time.Sleep(10 * time.Millisecond)
+
+		result := p.Healthcheck(ctx)
+		if result.Status != application.HealthStatusHealthy {
+			t.Fatalf("expected processor to be healthy, got: %s", result.Status)
+		}
+
+		stats, ok := result.Details.(queue.ProcessorStats)
+		if !ok {
+			t.Fatal("failed type assert processor stats")
+		}
+
+		if stats.Workers != 2 || stats.Busy != 0 || stats.Processed != 2 || stats.Failed != 1 {
+			t.Fatalf("unexpected processor stats: %+v", stats)
+		}
+
+		if stats.LastError != "service panicked: negative job" {
+			t.Fatalf("expected panic to be reported as last error, got: %q", stats.LastError)
+		}
+
+		queueStats, ok := stats.Queue.(queue.ChanQueueStats)
+		if !ok || queueStats.Capacity != 10 {
+			t.Fatalf("expected queue stats in details, got: %+v", stats.Queue)
+		}
+	})
 }
 
 type job struct {
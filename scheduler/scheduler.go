@@ -2,7 +2,11 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/platforma-dev/platforma/application"
@@ -11,15 +15,34 @@ import (
 	"github.com/google/uuid"
 )
 
+// ErrNotRunning is returned by Trigger when the scheduler is not running.
+var ErrNotRunning = errors.New("scheduler is not running")
+
 // Scheduler represents a periodic task runner that executes an action at fixed intervals.
 type Scheduler struct {
-	period time.Duration      // The interval between action executions
-	runner application.Runner // The runner to execute periodically
+	period  time.Duration      // The interval between action executions
+	runner  application.Runner // The runner to execute periodically
+	trigger chan struct{}      // Requests an immediate execution
+	running atomic.Bool
+
+	mu        sync.Mutex
+	runs      int
+	failures  int
+	lastRunAt time.Time
+	lastError string
+}
+
+// Stats holds execution counters of a Scheduler.
+type Stats struct {
+	Runs      int        `json:"runs"`
+	Failures  int        `json:"failures"` // Number of runs that returned an error or panicked
+	LastRunAt *time.Time `json:"lastRunAt,omitempty"`
+	LastError string     `json:"lastError,omitempty"` // Error of the latest run, empty if it succeeded
 }
 
 // New creates a new Scheduler instance with the specified period and action.
 func New(period time.Duration, runner application.Runner) *Scheduler {
-	return &Scheduler{period: period, runner: runner}
+	return &Scheduler{period: period, runner: runner, trigger: make(chan struct{}, 1)}
 }
 
 // Run starts the scheduler and executes the runner at the configured interval.
@@ -28,20 +51,101 @@ func (s *Scheduler) Run(ctx context.Context) error {
 	ticker := time.NewTicker(s.period)
 	defer ticker.Stop()
 
+	s.running.Store(true)
+	defer s.running.Store(false)
+
 	for {
 		select {
 		case <-ticker.C:
-			runCtx := context.WithValue(ctx, log.TraceIDKey, uuid.NewString())
-			log.InfoContext(runCtx, "scheduler task started")
-
-			err := s.runner.Run(runCtx)
-			if err != nil {
-				log.ErrorContext(runCtx, "error in scheduler", "error", err)
-			}
-
-			log.InfoContext(runCtx, "scheduler task finished")
+			_ = s.RunOnce(ctx)
+		case <-s.trigger:
+			_ = s.RunOnce(ctx)
 		case <-ctx.Done():
 			return fmt.Errorf("scheduler context canceled: %w", ctx.Err())
 		}
 	}
 }
+
+// Trigger requests an immediate execution of the runner without waiting for the next interval.
+// If an execution is already requested, the call has no effect.
+func (s *Scheduler) Trigger() error {
+	if !s.running.Load() {
+		return ErrNotRunning
+	}
+
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// Stats returns execution counters of the scheduler.
+func (s *Scheduler) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := Stats{Runs: s.runs, Failures: s.failures, LastError: s.lastError}
+	if !s.lastRunAt.IsZero() {
+		lastRunAt := s.lastRunAt
+		stats.LastRunAt = &lastRunAt
+	}
+
+	return stats
+}
+
+// Healthcheck reports execution counters of the scheduler.
+// The scheduler is degraded if its latest run failed.
+func (s *Scheduler) Healthcheck(_ context.Context) application.HealthCheckResult {
+	stats := s.Stats()
+
+	if stats.LastError != "" {
+		return application.HealthCheckResult{Status: application.HealthStatusDegraded, Message: stats.LastError, Details: stats}
+	}
+
+	return application.HealthCheckResult{Status: application.HealthStatusHealthy, Details: stats}
+}
+
+// RunOnce runs the task once and returns its error. Panics are returned as application.ErrServicePanicked.
+// It does not require the scheduler to be running, so it can be used to run the job by hand.
+func (s *Scheduler) RunOnce(ctx context.Context) error {
+	runCtx := context.WithValue(ctx, log.TraceIDKey, uuid.NewString())
+	log.InfoContext(runCtx, "scheduler task started")
+
+	err := s.runOnce(runCtx)
+	if err != nil {
+		var panicErr *application.ErrServicePanicked
+		if errors.As(err, &panicErr) {
+			log.ErrorContext(runCtx, "error in scheduler", "error", err, "stack", string(panicErr.Stack))
+		} else {
+			log.ErrorContext(runCtx, "error in scheduler", "error", err)
+		}
+	}
+
+	s.mu.Lock()
+	s.runs++
+	s.lastRunAt = time.Now()
+	s.lastError = ""
+	if err != nil {
+		s.failures++
+		s.lastError = err.Error()
+	}
+	s.mu.Unlock()
+
+	log.InfoContext(runCtx, "scheduler task finished")
+
+	return err
+}
+
+// runOnce runs the runner and converts its panic into application.ErrServicePanicked
+// so that a single failed run does not stop the scheduler.
+func (s *Scheduler) runOnce(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &application.ErrServicePanicked{Value: r, Stack: debug.Stack()}
+		}
+	}()
+
+	return s.runner.Run(ctx)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -73,3 +74,88 @@ func TestContextDecline(t *testing.T) {
 		t.Error("expected error, got nil")
 	}
 }
+
+func TestTrigger(t *testing.T) {
+	t.Parallel()
+
+	var runs atomic.Int32
+	s := scheduler.New(time.Hour, application.RunnerFunc(func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	}))
+
+	err := s.Trigger()
+	if !errors.Is(err, scheduler.ErrNotRunning) {
+		t.Fatalf("expected ErrNotRunning, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	time.Sleep(10 * time.Millisecond)
+
+	err = s.Trigger()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+
+	if runs.Load() != 1 {
+		t.Errorf("expected 1 run, got %d", runs.Load())
+	}
+}
+
+func TestPanicRun(t *testing.T) {
+	t.Parallel()
+
+	var runs atomic.Int32
+	s := scheduler.New(10*time.Millisecond, application.RunnerFunc(func(ctx context.Context) error {
+		if runs.Add(1) == 1 {
+			panic("boom")
+		}
+		return nil
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	time.Sleep(15 * time.Millisecond)
+
+	stats := s.Stats()
+	if stats.Failures != 1 || stats.LastError != "service panicked: boom" {
+		t.Fatalf("expected panicked run to be reported, got %+v", stats)
+	}
+
+	if result := s.Healthcheck(ctx); result.Status != application.HealthStatusDegraded {
+		t.Fatalf("expected degraded scheduler after failed run, got %s", result.Status)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	stats = s.Stats()
+	if stats.Runs < 2 || stats.LastError != "" {
+		t.Fatalf("expected scheduler to keep running after panic, got %+v", stats)
+	}
+}
+
+func TestRunOnce(t *testing.T) {
+	t.Parallel()
+
+	runErr := errors.New("cleanup failed")
+	s := scheduler.New(time.Hour, application.RunnerFunc(func(ctx context.Context) error {
+		return runErr
+	}))
+
+	err := s.RunOnce(context.Background())
+	if !errors.Is(err, runErr) {
+		t.Fatalf("expected runner error, got %v", err)
+	}
+
+	stats := s.Stats()
+	if stats.Runs != 1 || stats.Failures != 1 {
+		t.Fatalf("expected run to be counted, got %+v", stats)
+	}
+}
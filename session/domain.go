@@ -1,5 +1,7 @@
 package session
 
+import "github.com/platforma-dev/platforma/application"
+
 type Domain struct {
 	Repository *Repository
 	Service    *Service
@@ -18,3 +20,29 @@ func New(db db) *Domain {
 		Service:    service,
 	}
 }
+
+// Provide builds the domain with the database connection resolved from the container.
+// Use it with application.ProvideDomain.
+func Provide(c *application.Container) (*Domain, error) {
+	var deps struct {
+		DB db `inject:""`
+	}
+
+	err := application.Inject(c, &deps)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(deps.DB), nil
+}
+
+// ProvideService resolves the domain from the container and returns its service,
+// so that other domains can depend on the session storage.
+func ProvideService(c *application.Container) (*Service, error) {
+	domain, err := application.Resolve[*Domain](c)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.Service, nil
+}
@@ -15,13 +15,16 @@ type db interface {
 	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
 }
 
+// Repository stores sessions. Get, Create and Delete come from the embedded database.CRUDRepository.
 type Repository struct {
+	*database.CRUDRepository[Session]
 	db db
 }
 
 func NewRepository(db db) *Repository {
 	return &Repository{
-		db: db,
+		CRUDRepository: database.NewCRUDRepository[Session](db, "sessions"),
+		db:             db,
 	}
 }
 
@@ -38,15 +41,6 @@ func (r *Repository) Migrations() []database.Migration {
 	}}
 }
 
-func (r *Repository) Get(ctx context.Context, id string) (*Session, error) {
-	var session Session
-	err := r.db.GetContext(ctx, &session, "SELECT * FROM sessions WHERE id = $1", id)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get session by id: %w", err)
-	}
-	return &session, nil
-}
-
 func (r *Repository) GetByUserId(ctx context.Context, userID string) (*Session, error) {
 	var session Session
 	err := r.db.GetContext(ctx, &session, "SELECT * FROM sessions WHERE \"user\" = $1", userID)
@@ -56,29 +50,6 @@ func (r *Repository) GetByUserId(ctx context.Context, userID string) (*Session,
 	return &session, nil
 }
 
-func (r *Repository) Create(ctx context.Context, session *Session) error {
-	query := `
-		INSERT INTO sessions (id, "user", created, expires)
-		VALUES (:id, :user, :created, :expires)
-	`
-	_, err := r.db.NamedExecContext(ctx, query, session)
-	if err != nil {
-		return fmt.Errorf("failed to create session: %w", err)
-	}
-	return nil
-}
-
-func (r *Repository) Delete(ctx context.Context, id string) error {
-	query := `
-		DELETE FROM sessions WHERE id = $1
-	`
-	_, err := r.db.ExecContext(ctx, query, id)
-	if err != nil {
-		return fmt.Errorf("failed to delete session: %w", err)
-	}
-	return nil
-}
-
 func (r *Repository) DeleteByUserId(ctx context.Context, userId string) error {
 	query := `
 		DELETE FROM sessions WHERE "user" = $1
@@ -2,6 +2,8 @@ package session
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
@@ -30,8 +32,13 @@ func (s *Service) GetByUserId(ctx context.Context, id string) (*Session, error)
 	return s.repo.GetByUserId(ctx, id)
 }
 
+// DeleteSession deletes the session. Deleting a missing session is not an error, so logging out twice succeeds.
 func (s *Service) DeleteSession(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
+	err := s.repo.Delete(ctx, id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	return nil
 }
 
 func (s *Service) GetUserIdFromSessionId(ctx context.Context, id string) (string, error) {